@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amulcse/try/internal/tags"
+	"github.com/amulcse/try/internal/tui"
+)
+
+// archiveDir holds archived tries inside the tries root. Like every dot
+// directory it is hidden from the selector.
+const archiveDir = ".archive"
+
+// scriptArchive moves the tries into <root>/.archive, keeping their
+// relative paths
+func scriptArchive(paths []tui.MarkedPath, basePath string) []string {
+	return scriptRelocate(paths, basePath, filepath.Join(basePath, archiveDir), "Archived")
+}
+
+// scriptMove moves the tries into another tries root, keeping their
+// relative paths so nested tries stay in their year/month directories
+func scriptMove(paths []tui.MarkedPath, basePath, target string) []string {
+	return scriptRelocate(paths, basePath, target, "Moved")
+}
+
+// scriptRelocate moves each try below dest without overwriting anything,
+// then removes year/month directories the move left empty
+func scriptRelocate(paths []tui.MarkedPath, basePath, dest, verb string) []string {
+	cmds := []string{
+		fmt.Sprintf("mkdir -p %s", q(dest)),
+		fmt.Sprintf("cd %s", q(basePath)),
+	}
+	store, _ := tags.Load(tags.File(basePath))
+	buckets := []string{}
+	for _, item := range paths {
+		dst := filepath.Join(dest, item.Basename)
+		if parent := filepath.Dir(item.Basename); parent != "." {
+			cmds = append(cmds, fmt.Sprintf("mkdir -p %s", q(filepath.Dir(dst))))
+			for dir := parent; dir != "."; dir = filepath.Dir(dir) {
+				if indexOfString(buckets, dir) < 0 {
+					buckets = append(buckets, dir)
+				}
+			}
+		}
+		cmds = append(cmds, fmt.Sprintf("test ! -e %s && mv %s %s", q(dst), q(item.Basename), q(dst)))
+		cmds = append(cmds, scriptRetag(store, basePath, item.Basename, dest, item.Basename)...)
+		cmds = append(cmds, fmt.Sprintf("echo %s", q(fmt.Sprintf("%s %s to %s", verb, item.Basename, dst))))
+	}
+	// Months before years, so a year emptied by the move goes too
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return strings.Count(buckets[i], "/") > strings.Count(buckets[j], "/")
+	})
+	for _, dir := range buckets {
+		cmds = append(cmds, fmt.Sprintf("( rmdir %s 2>/dev/null || true )", q(dir)))
+	}
+	cwd, _ := os.Getwd()
+	cmds = append(cmds, fmt.Sprintf("( cd %s 2>/dev/null || cd \"$HOME\" )", q(cwd)))
+	return cmds
+}
+
+// scriptExport packs the tries into a tarball in the current directory
+func scriptExport(paths []tui.MarkedPath, basePath string) []string {
+	cwd, _ := os.Getwd()
+	base := "try-export-" + time.Now().Format("2006-01-02")
+	archive := filepath.Join(cwd, base+".tar.gz")
+	for i := 2; pathExists(archive); i++ {
+		archive = filepath.Join(cwd, fmt.Sprintf("%s-%d.tar.gz", base, i))
+	}
+
+	names := make([]string, len(paths))
+	for i, item := range paths {
+		names[i] = q(item.Basename)
+	}
+	return []string{
+		fmt.Sprintf("tar -czf %s -C %s %s", q(archive), q(basePath), strings.Join(names, " ")),
+		fmt.Sprintf("echo %s", q(fmt.Sprintf("Exported %d %s to %s", len(paths), pluralTries(len(paths)), archive))),
+	}
+}
+
+// tagTries records the tag changes right away; the script only reports them
+func tagTries(paths []tui.MarkedPath, basePath, input string) []string {
+	add, remove := tags.Parse(input)
+	if len(add) == 0 && len(remove) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no tags given\n")
+		os.Exit(1)
+	}
+
+	store, err := tags.Load(tags.File(basePath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot read tags: %v\n", err)
+		os.Exit(1)
+	}
+	names := make([]string, len(paths))
+	for i, item := range paths {
+		names[i] = item.Basename
+	}
+	if err := store.Update(names, add, remove); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot save tags: %v\n", err)
+		os.Exit(1)
+	}
+
+	changes := []string{}
+	for _, tag := range add {
+		changes = append(changes, "+"+tag)
+	}
+	for _, tag := range remove {
+		changes = append(changes, "-"+tag)
+	}
+	return []string{fmt.Sprintf("echo %s", q(fmt.Sprintf("Tagged %d %s: %s", len(paths), pluralTries(len(paths)), strings.Join(changes, " "))))}
+}
+
+// scriptRetag moves the tags of the try at name under root to newName
+// under newRoot, or drops them when newName is "", so they follow the try
+// once the command before it moved or removed the directory. Tries without
+// tags need nothing.
+func scriptRetag(store *tags.Store, root, name, newRoot, newName string) []string {
+	name = filepath.ToSlash(name)
+	list := store.Tags(name)
+	if len(list) == 0 {
+		return nil
+	}
+	file, tmp := tags.File(root), tags.File(root)+".tmp"
+	cmds := []string{fmt.Sprintf("TRY_TAGGED=%s awk -F '\\t' %s %s > %s && mv %s %s",
+		q(name), q(`$1 != ENVIRON["TRY_TAGGED"]`), q(file), q(tmp), q(tmp), q(file))}
+	if newName != "" {
+		newFile := tags.File(newRoot)
+		cmds = append(cmds,
+			fmt.Sprintf("mkdir -p %s", q(filepath.Dir(newFile))),
+			fmt.Sprintf("printf '%%s\\t%%s\\n' %s %s >> %s", q(filepath.ToSlash(newName)), q(strings.Join(list, ",")), q(newFile)))
+	}
+	return cmds
+}
+
+func pluralTries(n int) string {
+	if n == 1 {
+		return "try"
+	}
+	return "tries"
+}
+
+func indexOfString(list []string, item string) int {
+	for i, s := range list {
+		if s == item {
+			return i
+		}
+	}
+	return -1
+}
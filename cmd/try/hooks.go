@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amulcse/try/internal/config"
+)
+
+// hooks emits the user's lifecycle hooks into generated scripts. Hooks come
+// from the config file and from executables in <root>/.try/hooks/<event>.
+// Each hook runs in a subshell inside the try directory with TRY_* variables
+// describing the operation.
+type hooks struct {
+	root string
+	cfg  *config.Config
+}
+
+func newHooks(root string, cfg *config.Config) *hooks {
+	return &hooks{root: root, cfg: cfg}
+}
+
+// session returns the configured session manager, if any
+func (h *hooks) session() string {
+	if h == nil || h.cfg == nil {
+		return ""
+	}
+	return h.cfg.Session
+}
+
+// hookVar is an extra environment variable passed to a hook
+type hookVar struct {
+	Name  string
+	Value string
+}
+
+// commands returns the hook invocations for event, in the order the config
+// hooks are listed followed by the executable hook, if any
+func (h *hooks) commands(event string) []string {
+	if h == nil {
+		return nil
+	}
+	cmds := []string{}
+	if h.cfg != nil {
+		for _, cmd := range h.cfg.Hooks[event] {
+			cmds = append(cmds, "sh -c "+q(cmd))
+		}
+	}
+	exe := filepath.Join(h.root, ".try", "hooks", event)
+	if info, err := os.Stat(exe); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+		cmds = append(cmds, q(exe))
+	}
+	return cmds
+}
+
+// script returns one script line per hook for event, run against dir.
+// Failures of non-fatal hooks are reported and ignored; a failing fatal
+// hook fails its line, which stops the rest of the && chain.
+func (h *hooks) script(event, dir, action string, fatal bool, vars ...hookVar) []string {
+	cmds := h.commands(event)
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	env := []string{
+		"TRY_HOOK=" + event,
+		"TRY_ACTION=" + action,
+		"TRY_ROOT=" + h.root,
+		"TRY_DIR=" + dir,
+		"TRY_NAME=" + filepath.Base(dir),
+	}
+	for _, v := range vars {
+		env = append(env, v.Name+"="+v.Value)
+	}
+	quoted := make([]string, len(env))
+	for i, e := range env {
+		quoted[i] = q(e)
+	}
+	prefix := "/usr/bin/env " + strings.Join(quoted, " ")
+
+	onFailure := fmt.Sprintf("echo %s >&2", q(fmt.Sprintf("try: %s hook failed in %s", event, dir)))
+	if fatal {
+		onFailure = fmt.Sprintf("{ echo %s >&2; exit 1; }", q(fmt.Sprintf("try: %s hook failed for %s, aborting", event, filepath.Base(dir))))
+	}
+
+	lines := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		lines = append(lines, fmt.Sprintf("( cd %s && %s %s || %s )", q(dir), prefix, cmd, onFailure))
+	}
+	return lines
+}
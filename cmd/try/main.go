@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/amulcse/try/internal/config"
+	"github.com/amulcse/try/internal/fuzzy"
+	"github.com/amulcse/try/internal/tags"
 	"github.com/amulcse/try/internal/tui"
 )
 
@@ -24,9 +26,9 @@ func main() {
 	if disabled {
 		disableColors = true
 	}
-	args, disabled = removeFlag(args, "--no-expand-tokens")
-	if disabled {
-		disableColors = true
+	args, rawTokens := removeFlag(args, "--no-expand-tokens")
+	if rawTokens {
+		tui.ShowTokens()
 	}
 	if os.Getenv("NO_COLOR") != "" {
 		disableColors = true
@@ -62,6 +64,61 @@ func main() {
 	andKeysRaw := extractOptionWithValue(&args, "--and-keys")
 	andConfirm := extractOptionWithValue(&args, "--and-confirm")
 	andKeys := parseTestKeys(andKeysRaw)
+	args, andSaveIndex := removeFlag(args, "--and-save-index")
+	if andSaveIndex {
+		tui.SaveIndexInTests()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config: %v\n", err)
+	}
+	theme, err := tui.LoadTheme(cfg.Theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring theme: %v\n", err)
+	}
+	tui.SetTheme(theme, tui.DetectColorDepth(os.Getenv("COLORTERM"), os.Getenv("TERM")))
+
+	// Session flags override the config default
+	var useTmux, useZellij, noSession bool
+	args, useTmux = removeFlag(args, "--tmux")
+	args, useZellij = removeFlag(args, "--zellij")
+	args, noSession = removeFlag(args, "--no-session")
+	switch {
+	case noSession:
+		cfg.Session = ""
+	case useTmux:
+		cfg.Session = config.SessionTmux
+	case useZellij:
+		cfg.Session = config.SessionZellij
+	}
+
+	if sortMode := extractOptionWithValue(&args, "--sort"); sortMode != "" {
+		if !config.ValidSort(sortMode) {
+			fmt.Fprintf(os.Stderr, "Error: unknown sort mode: %s (use %s)\n", sortMode, strings.Join(config.SortModes, ", "))
+			os.Exit(1)
+		}
+		cfg.Sort = sortMode
+	}
+	if caseMode := extractOptionWithValue(&args, "--case"); caseMode != "" {
+		if !config.ValidCase(caseMode) {
+			fmt.Fprintf(os.Stderr, "Error: unknown case mode: %s (use %s)\n", caseMode, strings.Join(fuzzy.CaseModes, ", "))
+			os.Exit(1)
+		}
+		cfg.Match.Case = caseMode
+	}
+	if height := extractOptionWithValue(&args, "--height"); height != "" {
+		if !config.ValidHeight(height) {
+			fmt.Fprintf(os.Stderr, "Error: invalid height: %s (use rows such as 15, or a percentage such as 40%%)\n", height)
+			os.Exit(1)
+		}
+		cfg.Height = height
+	}
+	var inline bool
+	args, inline = removeFlag(args, "--inline")
+	if inline && cfg.Height == "" {
+		cfg.Height = config.DefaultHeight
+	}
 
 	var command string
 	if len(args) > 0 {
@@ -74,7 +131,7 @@ func main() {
 		config.PrintHelp(triesPath)
 		os.Exit(2)
 	case "clone":
-		cmds := cmdClone(args, triesPath)
+		cmds := cmdClone(args, triesPath, cfg)
 		emitScript(cmds)
 		os.Exit(0)
 	case "init":
@@ -90,9 +147,15 @@ func main() {
 			if len(args) > 0 {
 				args = args[1:]
 			}
-			cmds := cmdClone(args, triesPath)
+			cmds := cmdClone(args, triesPath, cfg)
 			emitScript(cmds)
 			os.Exit(0)
+		case "migrate-layout":
+			migrateLayout(args[1:], triesPath)
+		case "trust", "untrust":
+			cmdTrust(args[1:], cfg, sub == "trust")
+		case "keys":
+			cmdKeys(cfg)
 		case "worktree":
 			if len(args) > 0 {
 				args = args[1:]
@@ -103,15 +166,15 @@ func main() {
 				args = args[1:]
 			}
 			repoDir := repoDirFromArg(repo)
-			fullPath := worktreePath(triesPath, repoDir, strings.Join(args, " "))
-			cmds := scriptWorktree(fullPath, repoDir, true)
+			fullPath := worktreePath(triesPath, repoDir, strings.Join(args, " "), cfg)
+			cmds := scriptWorktree(fullPath, repoDir, true, newHooks(triesPath, cfg))
 			emitScript(cmds)
 			os.Exit(0)
 		case "cd":
 			if len(args) > 0 {
 				args = args[1:]
 			}
-			cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm)
+			cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm, cfg)
 			if cmds == nil {
 				fmt.Println("Cancelled.")
 				os.Exit(1)
@@ -119,7 +182,7 @@ func main() {
 			emitScript(cmds)
 			os.Exit(0)
 		default:
-			cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm)
+			cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm, cfg)
 			if cmds == nil {
 				fmt.Println("Cancelled.")
 				os.Exit(1)
@@ -127,6 +190,12 @@ func main() {
 			emitScript(cmds)
 			os.Exit(0)
 		}
+	case "migrate-layout":
+		migrateLayout(args, triesPath)
+	case "trust", "untrust":
+		cmdTrust(args, cfg, command == "trust")
+	case "keys":
+		cmdKeys(cfg)
 	case "worktree":
 		repo := ""
 		if len(args) > 0 {
@@ -134,13 +203,13 @@ func main() {
 			args = args[1:]
 		}
 		repoDir := repoDirFromArg(repo)
-		fullPath := worktreePath(triesPath, repoDir, strings.Join(args, " "))
-		cmds := scriptWorktree(fullPath, repoDir, true)
+		fullPath := worktreePath(triesPath, repoDir, strings.Join(args, " "), cfg)
+		cmds := scriptWorktree(fullPath, repoDir, true, newHooks(triesPath, cfg))
 		emitScript(cmds)
 		os.Exit(0)
 	default:
 		args = append([]string{command}, args...)
-		cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm)
+		cmds := cmdCd(args, triesPath, andType, andExit, andKeys, andConfirm, cfg)
 		if cmds == nil {
 			fmt.Println("Cancelled.")
 			os.Exit(1)
@@ -150,7 +219,18 @@ func main() {
 	}
 }
 
-func cmdClone(args []string, triesPath string) []string {
+// cmdKeys prints the selector's effective key bindings, and any configured
+// binding that was skipped
+func cmdKeys(cfg *config.Config) {
+	keymap, errs := tui.NewKeymap(cfg)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	keymap.Describe(os.Stderr)
+	os.Exit(0)
+}
+
+func cmdClone(args []string, triesPath string, cfg *config.Config) []string {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: git URI required for clone command")
 		fmt.Fprintln(os.Stderr, "Usage: try clone <git-uri> [name]")
@@ -161,13 +241,26 @@ func cmdClone(args []string, triesPath string) []string {
 	if len(args) > 1 {
 		customName = args[1]
 	}
-	name, err := generateCloneDirectoryName(gitURI, customName)
-	if err != nil {
+	name, err := generateCloneDirectoryName(gitURI, customName, cfg)
+	if errors.Is(err, errInvalidName) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Unable to parse git URI: %s\n", gitURI)
 		os.Exit(1)
 	}
-	fullPath := filepath.Join(triesPath, name)
-	return scriptClone(fullPath, gitURI)
+	fullPath := filepath.Join(cfg.ParentDir(triesPath, time.Now()), name)
+	return scriptClone(fullPath, gitURI, newHooks(triesPath, cfg))
+}
+
+func migrateLayout(args []string, triesPath string) {
+	cmds := cmdMigrateLayout(args, triesPath)
+	if cmds == nil {
+		fmt.Fprintln(os.Stderr, "Nothing to migrate.")
+		os.Exit(0)
+	}
+	emitScript(cmds)
+	os.Exit(0)
 }
 
 func cmdInit(args []string, triesPath string) {
@@ -216,9 +309,21 @@ end
 	os.Exit(0)
 }
 
-func cmdCd(args []string, triesPath, andType string, andExit bool, andKeys []string, andConfirm string) []string {
+func cmdCd(args []string, triesPath, andType string, andExit bool, andKeys []string, andConfirm string, cfg *config.Config) []string {
 	if len(args) > 0 && args[0] == "clone" {
-		return cmdClone(args[1:], triesPath)
+		return cmdClone(args[1:], triesPath, cfg)
+	}
+
+	contentSearch := false
+	if len(args) > 0 && args[0] == "grep" {
+		var contents bool
+		args, contents = removeFlag(args[1:], "--contents")
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Usage: try grep [--contents] <pattern>")
+			os.Exit(1)
+		}
+		contentSearch = true
+		cfg.Grep.Contents = cfg.Grep.Contents || contents
 	}
 
 	if len(args) > 0 && strings.HasPrefix(args[0], ".") {
@@ -231,53 +336,80 @@ func cmdCd(args []string, triesPath, andType string, andExit bool, andKeys []str
 			fmt.Fprintln(os.Stderr, "Usage: try . <name>")
 			os.Exit(1)
 		}
-		base := ""
-		if strings.TrimSpace(custom) != "" {
-			base = strings.ReplaceAll(custom, " ", "-")
-		} else {
-			base = filepath.Base(repoDir)
+		source := custom
+		if strings.TrimSpace(custom) == "" {
+			source = filepath.Base(repoDir)
 		}
-		datePrefix := time.Now().Format("2006-01-02")
-		base = resolveUniqueNameWithVersioning(triesPath, datePrefix, base)
-		fullPath := filepath.Join(triesPath, fmt.Sprintf("%s-%s", datePrefix, base))
+		base := cfg.Slugify(source)
+		if base == "" {
+			fmt.Fprintf(os.Stderr, "Error: invalid name: %s\n", source)
+			os.Exit(1)
+		}
+		now := time.Now()
+		parentDir := cfg.ParentDir(triesPath, now)
+		datePrefix := now.Format("2006-01-02")
+		base = resolveUniqueNameWithVersioning(parentDir, datePrefix, base)
+		fullPath := filepath.Join(parentDir, fmt.Sprintf("%s-%s", datePrefix, base))
 		if _, err := os.Stat(filepath.Join(repoDir, ".git")); err == nil {
-			return scriptWorktree(fullPath, repoDir, false)
+			return scriptWorktree(fullPath, repoDir, false, newHooks(triesPath, cfg))
 		}
-		return scriptMkdirCd(fullPath)
+		return scriptMkdirCd(fullPath, newHooks(triesPath, cfg))
 	}
 
 	searchTerm := strings.Join(args, " ")
 	fields := strings.Fields(searchTerm)
-	if len(fields) > 0 && isGitURI(fields[0]) {
+	if !contentSearch && len(fields) > 0 && isGitURI(fields[0]) {
 		gitURI := fields[0]
 		custom := ""
 		if len(fields) > 1 {
 			custom = strings.Join(fields[1:], " ")
 		}
-		name, err := generateCloneDirectoryName(gitURI, custom)
-		if err != nil {
+		name, err := generateCloneDirectoryName(gitURI, custom, cfg)
+		if errors.Is(err, errInvalidName) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		} else if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: Unable to parse git URI: %s\n", gitURI)
 			os.Exit(1)
 		}
-		fullPath := filepath.Join(triesPath, name)
-		return scriptClone(fullPath, gitURI)
+		fullPath := filepath.Join(cfg.ParentDir(triesPath, time.Now()), name)
+		return scriptClone(fullPath, gitURI, newHooks(triesPath, cfg))
 	}
 
-	selector := tui.NewSelector(searchTerm, triesPath, andType, andExit, andKeys, andConfirm)
+	selector := tui.NewSelector(searchTerm, triesPath, andType, andExit, andKeys, andConfirm, cfg)
+	for _, err := range selector.KeymapErrors() {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring key binding: %v\n", err)
+	}
+	selector.SetContentSearch(contentSearch)
 	result := selector.Run()
 	if result == nil {
 		return nil
 	}
 
+	h := newHooks(triesPath, cfg)
 	switch result.Type {
 	case "delete":
-		return scriptDelete(result.Paths, result.BasePath)
+		return scriptDelete(result.Paths, result.BasePath, h)
+	case tui.BulkArchive:
+		return scriptArchive(result.Paths, result.BasePath)
+	case tui.BulkMove:
+		return scriptMove(result.Paths, result.BasePath, result.Target)
+	case tui.BulkExport:
+		return scriptExport(result.Paths, result.BasePath)
+	case tui.BulkTag:
+		return tagTries(result.Paths, result.BasePath, result.Tags)
 	case "mkdir":
-		return scriptMkdirCd(result.Path)
+		return scriptMkdirCd(result.Path, h)
 	case "rename":
-		return scriptRename(result.BasePath, result.OldName, result.NewName)
+		return scriptRename(triesPath, result.BasePath, result.OldName, result.NewName, h)
+	case tui.ActionEdit:
+		return scriptEdit(result.Path, h)
+	case tui.ActionTmux:
+		return scriptTmux(result.Path)
+	case tui.ActionPrint:
+		return scriptPrint(result.Path)
 	default:
-		return scriptCd(result.Path)
+		return scriptCd(result.Path, h)
 	}
 }
 
@@ -292,47 +424,75 @@ func repoDirFromArg(repo string) string {
 	return config.ExpandPath(repo)
 }
 
-func worktreePath(triesPath, repoDir, customName string) string {
-	base := ""
-	if strings.TrimSpace(customName) != "" {
-		base = strings.ReplaceAll(customName, " ", "-")
-	} else {
+func worktreePath(triesPath, repoDir, customName string, cfg *config.Config) string {
+	source := customName
+	if strings.TrimSpace(customName) == "" {
 		if real, err := filepath.EvalSymlinks(repoDir); err == nil {
-			base = filepath.Base(real)
+			source = filepath.Base(real)
 		} else {
-			base = filepath.Base(repoDir)
+			source = filepath.Base(repoDir)
 		}
 	}
-	datePrefix := time.Now().Format("2006-01-02")
-	base = resolveUniqueNameWithVersioning(triesPath, datePrefix, base)
-	return filepath.Join(triesPath, fmt.Sprintf("%s-%s", datePrefix, base))
+	base := cfg.Slugify(source)
+	if base == "" {
+		fmt.Fprintf(os.Stderr, "Error: invalid name: %s\n", source)
+		os.Exit(1)
+	}
+	now := time.Now()
+	parentDir := cfg.ParentDir(triesPath, now)
+	datePrefix := now.Format("2006-01-02")
+	base = resolveUniqueNameWithVersioning(parentDir, datePrefix, base)
+	return filepath.Join(parentDir, fmt.Sprintf("%s-%s", datePrefix, base))
 }
 
-func scriptCd(path string) []string {
-	return []string{
+func scriptCd(path string, h *hooks) []string {
+	return scriptEnter(path, h, "cd")
+}
+
+// scriptEnter cds into path, or switches to its tmux/zellij session when one
+// is configured, running the post-create hooks for new tries and the
+// post-cd hooks. Attaching blocks until the user detaches, so in session
+// mode the hooks run first, in the try, once the session exists. Session
+// mode sources no .tryrc, since the shell evaluating the script is not the
+// session's.
+func scriptEnter(path string, h *hooks, action string, vars ...hookVar) []string {
+	var hookCmds []string
+	if action == "mkdir" || action == "clone" || action == "worktree" {
+		hookCmds = append(hookCmds, h.script(config.HookPostCreate, path, action, false, vars...)...)
+	}
+	hookCmds = append(hookCmds, h.script(config.HookPostCd, path, action, false, vars...)...)
+
+	if session := h.session(); session != "" && action != tui.ActionEdit {
+		return scriptSession(path, session, hookCmds)
+	}
+	cmds := []string{
 		"clear",
 		fmt.Sprintf("touch %s", q(path)),
 		fmt.Sprintf("cd %s", q(path)),
 	}
+	if h != nil {
+		cmds = append(cmds, scriptTryrc(path, h.cfg)...)
+	}
+	return append(cmds, hookCmds...)
 }
 
-func scriptMkdirCd(path string) []string {
+func scriptMkdirCd(path string, h *hooks) []string {
 	cmds := []string{fmt.Sprintf("mkdir -p %s", q(path))}
-	cmds = append(cmds, scriptCd(path)...)
+	cmds = append(cmds, scriptEnter(path, h, "mkdir")...)
 	return cmds
 }
 
-func scriptClone(path, uri string) []string {
+func scriptClone(path, uri string, h *hooks) []string {
 	cmds := []string{
 		fmt.Sprintf("mkdir -p %s", q(path)),
 		fmt.Sprintf("echo %s", q(fmt.Sprintf("Using git clone to create this trial from %s.", uri))),
 		fmt.Sprintf("git clone %s %s", q(uri), q(path)),
 	}
-	cmds = append(cmds, scriptCd(path)...)
+	cmds = append(cmds, scriptEnter(path, h, "clone", hookVar{"TRY_GIT_URI", uri})...)
 	return cmds
 }
 
-func scriptWorktree(path, repo string, explicit bool) []string {
+func scriptWorktree(path, repo string, explicit bool, h *hooks) []string {
 	src := repo
 	if repo == "" || !explicit {
 		cwd, err := os.Getwd()
@@ -354,28 +514,119 @@ func scriptWorktree(path, repo string, explicit bool) []string {
 		fmt.Sprintf("echo %s", q(fmt.Sprintf("Using git worktree to create this trial from %s.", src))),
 		worktreeCmd,
 	}
-	cmds = append(cmds, scriptCd(path)...)
+	cmds = append(cmds, scriptEnter(path, h, "worktree", hookVar{"TRY_SOURCE_REPO", src})...)
 	return cmds
 }
 
-func scriptDelete(paths []tui.DeletePath, basePath string) []string {
+// scriptDelete runs every pre-delete hook before removing anything, so a
+// failing hook aborts the whole batch
+func scriptDelete(paths []tui.MarkedPath, basePath string, h *hooks) []string {
 	cmds := []string{fmt.Sprintf("cd %s", q(basePath))}
+	for _, item := range paths {
+		cmds = append(cmds, h.script(config.HookPreDelete, item.Path, "delete", true)...)
+	}
+	store, _ := tags.Load(tags.File(basePath))
 	for _, item := range paths {
 		cmds = append(cmds, fmt.Sprintf("test -d %s && rm -rf %s", q(item.Basename), q(item.Basename)))
+		cmds = append(cmds, scriptRetag(store, basePath, item.Basename, "", "")...)
 	}
 	cwd, _ := os.Getwd()
 	cmds = append(cmds, fmt.Sprintf("( cd %s 2>/dev/null || cd \"$HOME\" )", q(cwd)))
 	return cmds
 }
 
-func scriptRename(basePath, oldName, newName string) []string {
+// scriptRename renames a try inside basePath, a directory under the tries
+// root, and carries its tags over to the new name
+func scriptRename(root, basePath, oldName, newName string, h *hooks) []string {
 	newPath := filepath.Join(basePath, newName)
-	return []string{
+	cmds := []string{
 		fmt.Sprintf("cd %s", q(basePath)),
 		fmt.Sprintf("mv %s %s", q(oldName), q(newName)),
+	}
+	if rel, err := filepath.Rel(root, basePath); err == nil {
+		store, _ := tags.Load(tags.File(root))
+		cmds = append(cmds, scriptRetag(store, root, filepath.Join(rel, oldName), root, filepath.Join(rel, newName))...)
+	}
+	cmds = append(cmds,
 		fmt.Sprintf("echo %s", q(newPath)),
 		fmt.Sprintf("cd %s", q(newPath)),
+	)
+	if h != nil {
+		cmds = append(cmds, scriptTryrc(newPath, h.cfg)...)
+	}
+	vars := []hookVar{{"TRY_OLD_NAME", oldName}, {"TRY_NEW_NAME", newName}}
+	cmds = append(cmds, h.script(config.HookPostRename, newPath, "rename", false, vars...)...)
+	cmds = append(cmds, h.script(config.HookPostCd, newPath, "rename", false, vars...)...)
+	return cmds
+}
+
+// scriptEdit enters the try and opens it in $VISUAL or $EDITOR. The editor
+// variable is emitted unquoted so values like "code -w" keep their arguments.
+func scriptEdit(path string, h *hooks) []string {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmds := scriptEnter(path, h, tui.ActionEdit)
+	cmds = append(cmds, fmt.Sprintf("%s %s", editor, q(path)))
+	return cmds
+}
+
+// scriptTmux opens the try in a new tmux window when already inside tmux,
+// otherwise in a session named after it. The current shell stays put.
+func scriptTmux(path string) []string {
+	name := tmuxName(filepath.Base(path))
+	cmds := []string{fmt.Sprintf("touch %s", q(path))}
+	if os.Getenv("TMUX") != "" {
+		cmds = append(cmds, fmt.Sprintf("tmux new-window -n %s -c %s", q(name), q(path)))
+	} else {
+		cmds = append(cmds, fmt.Sprintf("tmux new-session -A -s %s -c %s", q(name), q(path)))
 	}
+	return cmds
+}
+
+// scriptSession switches to the session named after the try, creating it
+// with the try as its working directory, and runs hookCmds before switching.
+// Inside tmux the current client switches; outside it attaches. Zellij
+// cannot switch sessions from the command line, so inside zellij the try
+// opens in a new tab instead.
+func scriptSession(path, session string, hookCmds []string) []string {
+	name := tmuxName(filepath.Base(path))
+	cmds := []string{fmt.Sprintf("touch %s", q(path))}
+	switch session {
+	case config.SessionZellij:
+		cmds = append(cmds, hookCmds...)
+		if os.Getenv("ZELLIJ") != "" {
+			cmds = append(cmds, fmt.Sprintf("zellij action new-tab --name %s --cwd %s", q(name), q(path)))
+		} else {
+			cmds = append(cmds,
+				fmt.Sprintf("cd %s", q(path)),
+				fmt.Sprintf("zellij attach --create %s", q(name)))
+		}
+	default:
+		target := q("=" + name)
+		cmds = append(cmds, fmt.Sprintf("( tmux has-session -t %s 2>/dev/null || tmux new-session -d -s %s -c %s )", target, q(name), q(path)))
+		cmds = append(cmds, hookCmds...)
+		if os.Getenv("TMUX") != "" {
+			cmds = append(cmds, fmt.Sprintf("tmux switch-client -t %s", target))
+		} else {
+			cmds = append(cmds, fmt.Sprintf("tmux attach-session -t %s", target))
+		}
+	}
+	return cmds
+}
+
+func scriptPrint(path string) []string {
+	return []string{fmt.Sprintf("echo %s", q(path))}
+}
+
+// tmuxName makes name usable as a tmux session or window name, which may
+// not contain '.' or ':'
+func tmuxName(name string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(name)
 }
 
 func parseTestKeys(spec string) []string {
@@ -400,8 +651,12 @@ func parseTestKeys(spec string) []string {
 				keys = append(keys, "\x1b[C")
 			case "ENTER", "RETURN":
 				keys = append(keys, "\r")
+			case "TAB":
+				keys = append(keys, "\t")
 			case "ESC", "ESCAPE":
 				keys = append(keys, "\x1b")
+			case "F1":
+				keys = append(keys, "\x1bOP")
 			case "BACKSPACE", "BS":
 				keys = append(keys, "\x7f")
 			case "CTRL-A", "CTRLA":
@@ -414,25 +669,39 @@ func parseTestKeys(spec string) []string {
 				keys = append(keys, "\x05")
 			case "CTRL-F", "CTRLF":
 				keys = append(keys, "\x06")
+			case "CTRL-G", "CTRLG":
+				keys = append(keys, "\x07")
 			case "CTRL-H", "CTRLH":
 				keys = append(keys, "\x08")
 			case "CTRL-K", "CTRLK":
 				keys = append(keys, "\x0b")
 			case "CTRL-N", "CTRLN":
 				keys = append(keys, "\x0e")
+			case "CTRL-O", "CTRLO":
+				keys = append(keys, "\x0f")
 			case "CTRL-P", "CTRLP":
 				keys = append(keys, "\x10")
 			case "CTRL-R", "CTRLR":
 				keys = append(keys, "\x12")
+			case "CTRL-S", "CTRLS":
+				keys = append(keys, "\x13")
 			case "CTRL-T", "CTRLT":
 				keys = append(keys, "\x14")
 			case "CTRL-W", "CTRLW":
 				keys = append(keys, "\x17")
+			case "CTRL-X", "CTRLX":
+				keys = append(keys, "\x18")
+			case "ALT-ENTER", "ALT-RETURN":
+				keys = append(keys, "\x1b\r")
+			case "ALT-G":
+				keys = append(keys, "\x1bg")
 			default:
 				if strings.HasPrefix(up, "TYPE=") {
-					for _, ch := range up[5:] {
+					for _, ch := range tok[5:] {
 						keys = append(keys, string(ch))
 					}
+				} else if strings.HasPrefix(up, "PASTE=") {
+					keys = append(keys, tui.KeyPaste+tok[6:])
 				} else if len(tok) == 1 {
 					keys = append(keys, tok)
 				}
@@ -441,18 +710,7 @@ func parseTestKeys(spec string) []string {
 		return keys
 	}
 
-	keys := []string{}
-	i := 0
-	for i < len(spec) {
-		if spec[i] == 0x1b && i+2 < len(spec) && spec[i+1] == '[' {
-			keys = append(keys, spec[i:i+3])
-			i += 3
-		} else {
-			keys = append(keys, spec[i:i+1])
-			i++
-		}
-	}
-	return keys
+	return tui.SplitKeys(spec)
 }
 
 func splitTokens(spec string) []string {
@@ -566,16 +824,27 @@ func parseGitURI(uri string) (user string, repo string, host string, ok bool) {
 	return "", "", "", false
 }
 
-func generateCloneDirectoryName(gitURI, customName string) (string, error) {
+var errInvalidName = errors.New("invalid name")
+
+func generateCloneDirectoryName(gitURI, customName string, cfg *config.Config) (string, error) {
 	if strings.TrimSpace(customName) != "" {
-		return customName, nil
+		name := cfg.Slugify(customName)
+		if name == "" {
+			return "", fmt.Errorf("%w: %s", errInvalidName, customName)
+		}
+		return name, nil
 	}
 	user, repo, _, ok := parseGitURI(gitURI)
 	if !ok {
 		return "", errors.New("unable to parse git URI")
 	}
+	derived := fmt.Sprintf("%s-%s", user, repo)
+	name := cfg.Slugify(derived)
+	if name == "" {
+		return "", fmt.Errorf("%w: %s", errInvalidName, derived)
+	}
 	datePrefix := time.Now().Format("2006-01-02")
-	return fmt.Sprintf("%s-%s-%s", datePrefix, user, repo), nil
+	return fmt.Sprintf("%s-%s", datePrefix, name), nil
 }
 
 func isGitURI(arg string) bool {
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amulcse/try/internal/config"
+	"github.com/amulcse/try/internal/index"
+)
+
+type layoutMove struct {
+	From string // relative to the tries root
+	To   string // relative to the tries root
+}
+
+// cmdMigrateLayout moves every try between the flat and nested layouts.
+// Moves whose destination already exists are skipped and reported.
+func cmdMigrateLayout(args []string, triesPath string) []string {
+	target := ""
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if target != config.LayoutNested && target != config.LayoutFlat {
+		fmt.Fprintln(os.Stderr, "Error: migrate-layout requires a target layout")
+		fmt.Fprintln(os.Stderr, "Usage: try migrate-layout <nested|flat>")
+		os.Exit(1)
+	}
+
+	var moves []layoutMove
+	var buckets []string
+	if target == config.LayoutNested {
+		moves = planNestedMoves(triesPath)
+	} else {
+		moves, buckets = planFlatMoves(triesPath)
+	}
+	if len(moves) == 0 {
+		return nil
+	}
+	return scriptMigrate(triesPath, moves, buckets)
+}
+
+func planNestedMoves(triesPath string) []layoutMove {
+	entries, err := os.ReadDir(triesPath)
+	if err != nil {
+		return nil
+	}
+
+	moves := []layoutMove{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name[0] == '.' || index.IsBucketDir(name, 1) {
+			continue
+		}
+		created, ok := dateFromName(name)
+		if !ok {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			created = info.ModTime()
+		}
+		dest := filepath.Join(config.NestedDir(created), name)
+		if pathExists(filepath.Join(triesPath, dest)) {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %s already exists\n", name, dest)
+			continue
+		}
+		moves = append(moves, layoutMove{From: name, To: dest})
+	}
+	return moves
+}
+
+func planFlatMoves(triesPath string) ([]layoutMove, []string) {
+	years, err := os.ReadDir(triesPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	moves := []layoutMove{}
+	buckets := []string{}
+	claimed := map[string]bool{}
+	for _, year := range years {
+		if !year.IsDir() || !index.IsBucketDir(year.Name(), 1) {
+			continue
+		}
+		months, err := os.ReadDir(filepath.Join(triesPath, year.Name()))
+		if err != nil {
+			continue
+		}
+		for _, month := range months {
+			if !month.IsDir() || !index.IsBucketDir(month.Name(), 2) {
+				continue
+			}
+			monthDir := filepath.Join(year.Name(), month.Name())
+			tries, err := os.ReadDir(filepath.Join(triesPath, monthDir))
+			if err != nil {
+				continue
+			}
+			for _, try := range tries {
+				name := try.Name()
+				if !try.IsDir() || name[0] == '.' {
+					continue
+				}
+				from := filepath.Join(monthDir, name)
+				if claimed[name] || pathExists(filepath.Join(triesPath, name)) {
+					fmt.Fprintf(os.Stderr, "Skipping %s: %s already exists\n", from, name)
+					continue
+				}
+				claimed[name] = true
+				moves = append(moves, layoutMove{From: from, To: name})
+			}
+			buckets = append(buckets, monthDir)
+		}
+		buckets = append(buckets, year.Name())
+	}
+	return moves, buckets
+}
+
+// scriptMigrate emits the moves; emptied bucket directories are removed
+// with rmdir so anything left behind in them is never touched
+func scriptMigrate(triesPath string, moves []layoutMove, buckets []string) []string {
+	cmds := []string{fmt.Sprintf("cd %s", q(triesPath))}
+	made := map[string]bool{}
+	for _, move := range moves {
+		parent := filepath.Dir(move.To)
+		if parent != "." && !made[parent] {
+			cmds = append(cmds, fmt.Sprintf("mkdir -p %s", q(parent)))
+			made[parent] = true
+		}
+		cmds = append(cmds, fmt.Sprintf("test ! -e %s && mv %s %s", q(move.To), q(move.From), q(move.To)))
+	}
+	for _, bucket := range buckets {
+		cmds = append(cmds, fmt.Sprintf("( rmdir %s 2>/dev/null || true )", q(bucket)))
+	}
+	cwd, _ := os.Getwd()
+	cmds = append(cmds, fmt.Sprintf("( cd %s 2>/dev/null || cd \"$HOME\" )", q(cwd)))
+	return cmds
+}
+
+func dateFromName(name string) (time.Time, bool) {
+	if len(name) < 10 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", name[:10])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
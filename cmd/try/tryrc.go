@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amulcse/try/internal/config"
+	"github.com/amulcse/try/internal/trust"
+	"golang.org/x/term"
+)
+
+// scriptTryrc returns the line running the try's .tryrc, or nothing when
+// activation is off, the file is missing, or the user does not trust it.
+// The script carries the content that was checked rather than sourcing the
+// file, which could change between the check and the shell running it.
+func scriptTryrc(dir string, cfg *config.Config) []string {
+	if cfg == nil || !cfg.Tryrc.Enabled {
+		return nil
+	}
+	file := filepath.Join(dir, cfg.Tryrc.File)
+	if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot read %s: %v\n", file, err)
+		return nil
+	}
+
+	store, err := trust.Load(config.TrustFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot read trusted files: %v\n", err)
+		return nil
+	}
+	if !store.Trusted(file, data) && !promptTrust(file, data, store) {
+		return nil
+	}
+	return []string{fmt.Sprintf("eval %s", q(string(data)))}
+}
+
+// promptTrust asks on the terminal whether file, read as data, may be
+// sourced and records an approval. Without a terminal the file is never
+// trusted.
+func promptTrust(file string, data []byte, store *trust.Store) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "try: %s is not trusted, not sourcing it (run: try trust %s)\n", file, q(filepath.Dir(file)))
+		return false
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "try: %s is new or changed. Source it? [y/N/v(iew)] ", file)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			return false
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			if err := store.Allow(file, data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot record trust: %v\n", err)
+			}
+			return true
+		case "v", "view":
+			fmt.Fprintf(os.Stderr, "%s\n", data)
+		default:
+			fmt.Fprintf(os.Stderr, "try: not sourcing %s\n", file)
+			return false
+		}
+	}
+}
+
+// cmdTrust approves (or with allow=false, revokes) the .tryrc of a try
+func cmdTrust(args []string, cfg *config.Config, allow bool) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	file := filepath.Join(config.ExpandPath(dir), cfg.Tryrc.File)
+
+	store, err := trust.Load(config.TrustFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot read trusted files: %v\n", err)
+		os.Exit(1)
+	}
+	if allow {
+		var data []byte
+		if data, err = os.ReadFile(file); err == nil {
+			err = store.Allow(file, data)
+		}
+	} else {
+		err = store.Deny(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if allow {
+		fmt.Fprintf(os.Stderr, "Trusted %s\n", file)
+	} else {
+		fmt.Fprintf(os.Stderr, "Untrusted %s\n", file)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,135 @@
+// Package cellwidth measures strings in terminal cells, so text with wide
+// CJK characters, emoji and combining marks lines up
+package cellwidth
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cluster is one user-perceived character: a base rune with the marks,
+// variation selectors and zero-width-joined runes that follow it
+type Cluster struct {
+	Text  string
+	Runes int // runes in Text, to keep rune indices in step
+	Width int // terminal cells
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f'
+)
+
+// String returns how many cells s occupies. It does not understand
+// escape sequences; strip them first.
+func String(s string) int {
+	width := 0
+	for _, c := range Clusters(s) {
+		width += c.Width
+	}
+	return width
+}
+
+// Clusters splits s into user-perceived characters
+func Clusters(s string) []Cluster {
+	clusters := make([]Cluster, 0, len(s))
+	for len(s) > 0 {
+		c := next(s)
+		clusters = append(clusters, c)
+		s = s[len(c.Text):]
+	}
+	return clusters
+}
+
+// next returns the cluster at the start of s
+func next(s string) Cluster {
+	base, size := utf8.DecodeRuneInString(s)
+	c := Cluster{Runes: 1, Width: Rune(base)}
+	joined := false
+	for size < len(s) {
+		r, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case joined:
+			joined = false
+		case r == zeroWidthJoiner:
+			joined = true
+		case r == emojiVariation:
+			c.Width = 2
+		case isRegional(base) && isRegional(r) && c.Runes == 1:
+			// a pair of regional indicators is one flag
+		case isExtender(r):
+		default:
+			c.Text = s[:size]
+			return c
+		}
+		size += n
+		c.Runes++
+	}
+	c.Text = s
+	return c
+}
+
+// Rune returns the cells r occupies on its own
+func Rune(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case r < 0x300:
+		return 1
+	case isExtender(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// isExtender reports whether r joins the preceding character instead of
+// taking a cell: combining marks, format characters, variation selectors
+// and emoji skin tones
+func isExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) ||
+		r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// wideRanges are the East Asian Wide and Fullwidth blocks and the emoji
+// that default to emoji presentation
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f1e6, 0x1f1ff}, {0x1f200, 0x1f202},
+	{0x1f210, 0x1f23b}, {0x1f240, 0x1f248}, {0x1f250, 0x1f251}, {0x1f260, 0x1f265},
+	{0x1f300, 0x1f64f}, {0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f90c, 0x1f9ff},
+	{0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+func isWide(r rune) bool {
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
@@ -64,10 +64,26 @@ Usage:
   try worktree <name>   Create worktree from current git repo
   try --help            Show this help
 
+Options:
+  --path <dir>          Tries directory
+  --tmux, --zellij      Open the selected try in a session instead of cd
+  --no-session          Ignore the session set in the config
+  --sort <mode>         Initial order: score, recent, oldest, name, size, created
+  --case <mode>         Case sensitivity: smart, ignore, respect
+  --height <rows|N%%>    Draw the selector below the prompt, not full screen
+  --inline              Draw inline at the configured height, or 40%%
+
 Commands:
   init [path]           Output shell function definition
   clone <url> [name]    Clone git repo into date-prefixed directory
   worktree <name>       Create worktree in dated directory
+  grep [--contents] <pattern>
+                        Find tries by file names and notes (or all contents)
+  migrate-layout <nested|flat>
+                        Move tries into (or out of) YYYY/MM subdirectories
+  trust [dir]           Allow a try's .tryrc to be sourced on cd
+  untrust [dir]         Revoke that approval
+  keys                  Show the selector's key bindings
 
 Examples:
   try                   Open interactive selector
@@ -81,6 +97,7 @@ Manual mode (without alias):
 Defaults:
   Default path: ~/src/tries
   Current: %[2]s
-`, Version, currentPath)
+  Config file: %[3]s
+`, Version, currentPath, ConfigPath())
 	fmt.Print(text)
 }
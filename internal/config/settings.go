@@ -0,0 +1,305 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amulcse/try/internal/fuzzy"
+	"github.com/amulcse/try/internal/slug"
+)
+
+// Layout names for the tries directory
+const (
+	LayoutFlat   = "flat"
+	LayoutNested = "nested"
+)
+
+// Session managers that selecting a try can switch to instead of cd
+const (
+	SessionTmux   = "tmux"
+	SessionZellij = "zellij"
+)
+
+// Keymap presets of the selector
+const (
+	KeymapEmacs = "emacs"
+	KeymapVim   = "vim"
+)
+
+// Sort modes of the selector, in the order the sort key cycles through them
+const (
+	SortScore   = "score"
+	SortRecent  = "recent"
+	SortOldest  = "oldest"
+	SortName    = "name"
+	SortSize    = "size"
+	SortCreated = "created"
+)
+
+// SortModes lists every sort mode in cycle order
+var SortModes = []string{SortScore, SortRecent, SortOldest, SortName, SortSize, SortCreated}
+
+// ValidSort reports whether mode is a known sort mode
+func ValidSort(mode string) bool {
+	for _, m := range SortModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidCase reports whether mode is a known case mode
+func ValidCase(mode string) bool {
+	return slices.Contains(fuzzy.CaseModes, mode)
+}
+
+// ValidAlgorithm reports whether name is a known scoring algorithm
+func ValidAlgorithm(name string) bool {
+	return slices.Contains(fuzzy.Algorithms, name)
+}
+
+// Hook events, named after the files in <root>/.try/hooks/
+const (
+	HookPostCreate = "post-create"
+	HookPreDelete  = "pre-delete"
+	HookPostRename = "post-rename"
+	HookPostCd     = "post-cd"
+)
+
+// Config holds user settings loaded from the config file
+type Config struct {
+	// Layout is "flat" (<root>/<name>) or "nested" (<root>/YYYY/MM/<name>)
+	Layout string `json:"layout"`
+	// Depth is how many directory levels the selector scans below the root
+	Depth int `json:"depth"`
+	// Slug controls how names of created and renamed tries are sanitized
+	Slug slug.Options `json:"slug"`
+	// Hooks maps a hook event to shell commands run for it
+	Hooks map[string][]string `json:"hooks"`
+	// Tryrc controls sourcing a trusted per-try file on cd
+	Tryrc TryrcConfig `json:"tryrc"`
+	// Actions binds keys in the selector to what selecting a try does:
+	// "cd", "edit", "tmux" or "print". An empty action unbinds the key.
+	Actions map[string]string `json:"actions"`
+	// Session is "tmux" or "zellij" to open tries in a terminal session
+	// named after them instead of cd-ing the current shell
+	Session string `json:"session"`
+	// Sort is the selector's initial sort mode, e.g. "score" or "recent"
+	Sort string `json:"sort"`
+	// Match tunes fuzzy matching in the selector
+	Match MatchConfig `json:"match"`
+	// Grep tunes searching inside tries
+	Grep GrepConfig `json:"grep"`
+	// Keymap is the selector's key preset: "emacs", or "vim" for a normal
+	// mode with single-key commands
+	Keymap string `json:"keymap"`
+	// Keys binds keys to selector actions over the preset, e.g. "ctrl-j":
+	// "move-down". A "normal:" or "insert:" prefix limits a binding to one
+	// vim mode; an empty action unbinds the key.
+	Keys map[string]string `json:"keys"`
+	// Height draws the selector inline below the prompt, in "40%" of the
+	// terminal or "15" rows, instead of taking the whole screen
+	Height string `json:"height"`
+	// Display controls how the selector draws entries
+	Display DisplayConfig `json:"display"`
+	// Theme styles the selector: "dark", "light" or the path of a theme
+	// file, relative to this file's directory
+	Theme string `json:"theme"`
+	// Mouse lets the selector take clicks and the wheel; turning it off
+	// leaves the terminal's own text selection alone
+	Mouse bool `json:"mouse"`
+}
+
+// DefaultHeight is the height of --inline when none is configured
+const DefaultHeight = "40%"
+
+// ParseHeight returns the rows a selector height takes on a terminal of
+// rows rows: a percentage of them such as "40%", or a count such as "15"
+func ParseHeight(spec string, rows int) (int, bool) {
+	if percent, ok := strings.CutSuffix(spec, "%"); ok {
+		n, err := strconv.Atoi(percent)
+		if err != nil || n <= 0 || n > 100 {
+			return 0, false
+		}
+		return rows * n / 100, true
+	}
+	n, err := strconv.Atoi(spec)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// ValidHeight reports whether spec is a height ParseHeight takes
+func ValidHeight(spec string) bool {
+	_, ok := ParseHeight(spec, 100)
+	return ok
+}
+
+// DefaultFormat is the selector's entry line: the name on the left, and
+// after {right} the tags, the size once measured, the age and the score
+const DefaultFormat = "{icon} {name}{right}{dim}[{tags}  ][{size}, ]{age}, {score}{/dim}"
+
+// DisplayConfig controls how the selector draws entries
+type DisplayConfig struct {
+	// Format is the template of an entry line: fields such as {name} or
+	// {age}, style tokens such as {dim}, [groups] dropped when all their
+	// fields are empty, and {right} before the right-aligned part
+	Format string `json:"format"`
+	// Emoji draws icons as emoji; off, they are plain characters
+	Emoji bool `json:"emoji"`
+}
+
+// GrepConfig tunes searching inside tries
+type GrepConfig struct {
+	// Contents searches every text file, not only file names and notes
+	Contents bool `json:"contents"`
+}
+
+// MatchConfig tunes fuzzy matching
+type MatchConfig struct {
+	// Algorithm is "greedy" (fast, first occurrence of each character) or
+	// "optimal" (best alignment, slower)
+	Algorithm string `json:"algorithm"`
+	// Case is "smart" (case-sensitive for terms with an uppercase letter),
+	// "ignore" or "respect"
+	Case string `json:"case"`
+}
+
+// TryrcConfig controls per-try environment activation
+type TryrcConfig struct {
+	Enabled bool   `json:"enabled"`
+	File    string `json:"file"` // relative to the try, e.g. ".tryrc"
+}
+
+// DefaultConfig returns the settings used when no config file exists
+func DefaultConfig() *Config {
+	cfg := newConfig()
+	cfg.normalize()
+	return cfg
+}
+
+// newConfig returns the defaults that a config file is layered over
+func newConfig() *Config {
+	return &Config{
+		Layout: LayoutFlat,
+		Slug:   slug.DefaultOptions(),
+		Tryrc:  TryrcConfig{File: ".tryrc"},
+		Mouse:  true,
+		Display: DisplayConfig{
+			Format: DefaultFormat,
+			Emoji:  true,
+		},
+		Actions: map[string]string{
+			"ctrl-o":    "edit",
+			"ctrl-x":    "tmux",
+			"alt-enter": "print",
+		},
+	}
+}
+
+// ConfigPath returns the location of the config file
+func ConfigPath() string {
+	if env := os.Getenv("TRY_CONFIG"); env != "" {
+		return ExpandPath(env)
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(ExpandPath(xdg), "try", "config.json")
+	}
+	return ExpandPath(filepath.Join("~", ".config", "try", "config.json"))
+}
+
+// Load reads the config file, falling back to defaults when it is missing
+func Load() (*Config, error) {
+	data, err := os.ReadFile(ConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig(), nil
+		}
+		return DefaultConfig(), err
+	}
+	cfg := newConfig()
+	if len(strings.TrimSpace(string(data))) == 0 {
+		cfg.normalize()
+		return cfg, nil
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return DefaultConfig(), fmt.Errorf("%s: %w", ConfigPath(), err)
+	}
+	cfg.normalize()
+	return cfg, nil
+}
+
+func (c *Config) normalize() {
+	if c.Layout != LayoutNested {
+		c.Layout = LayoutFlat
+	}
+	if c.Session != SessionTmux && c.Session != SessionZellij {
+		c.Session = ""
+	}
+	if !ValidAlgorithm(c.Match.Algorithm) {
+		c.Match.Algorithm = fuzzy.AlgorithmGreedy
+	}
+	if !ValidCase(c.Match.Case) {
+		c.Match.Case = fuzzy.CaseSmart
+	}
+	if c.Keymap != KeymapVim {
+		c.Keymap = KeymapEmacs
+	}
+	if !ValidSort(c.Sort) {
+		c.Sort = SortScore
+	}
+	if c.Height != "" && !ValidHeight(c.Height) {
+		c.Height = ""
+	}
+	if c.Display.Format == "" {
+		c.Display.Format = DefaultFormat
+	}
+	if c.Tryrc.File == "" {
+		c.Tryrc.File = ".tryrc"
+	}
+	if c.Depth <= 0 {
+		c.Depth = 1
+		if c.Layout == LayoutNested {
+			c.Depth = 3
+		}
+	}
+}
+
+// TrustFile returns where approvals of per-try files are recorded
+func TrustFile() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(ExpandPath(xdg), "try", "trusted")
+	}
+	return ExpandPath(filepath.Join("~", ".local", "share", "try", "trusted"))
+}
+
+// Nested reports whether new tries go into year/month subdirectories
+func (c *Config) Nested() bool {
+	return c.Layout == LayoutNested
+}
+
+// ParentDir returns the directory a try created at t should live in
+func (c *Config) ParentDir(root string, t time.Time) string {
+	if !c.Nested() {
+		return root
+	}
+	return filepath.Join(root, NestedDir(t))
+}
+
+// Slugify sanitizes a name for a new or renamed try
+func (c *Config) Slugify(name string) string {
+	return slug.Make(name, c.Slug)
+}
+
+// NestedDir returns the YYYY/MM bucket for t, relative to the root
+func NestedDir(t time.Time) string {
+	return filepath.Join(t.Format("2006"), t.Format("01"))
+}
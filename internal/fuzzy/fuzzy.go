@@ -2,8 +2,18 @@
 package fuzzy
 
 import (
+	"cmp"
+	"context"
 	"math"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/amulcse/try/internal/slug"
 )
 
 // Item represents an item to be matched
@@ -11,85 +21,303 @@ type Item struct {
 	Text      string
 	Path      string
 	BaseScore float64
+	Mtime     time.Time // breaks score ties, newest first
+}
+
+// Scoring algorithms
+const (
+	// AlgorithmGreedy takes the first occurrence of each query rune. It is
+	// the fastest and the default.
+	AlgorithmGreedy = "greedy"
+	// AlgorithmOptimal scores every alignment of the query and keeps the
+	// best, preferring word boundaries, camelCase humps, consecutive runs
+	// and the name after the date prefix
+	AlgorithmOptimal = "optimal"
+)
+
+// Algorithms lists the scoring algorithms, the default first
+var Algorithms = []string{AlgorithmGreedy, AlgorithmOptimal}
+
+// Case sensitivity modes
+const (
+	// CaseIgnore matches regardless of case
+	CaseIgnore = "ignore"
+	// CaseSmart is CaseIgnore for terms in lowercase and CaseRespect for
+	// terms with an uppercase letter. Matching an uppercase letter earns a
+	// bonus, so "api" ranks "myAPI" above "rapid".
+	CaseSmart = "smart"
+	// CaseRespect matches case exactly
+	CaseRespect = "respect"
+)
+
+// CaseModes lists the case modes in the order the selector cycles them
+var CaseModes = []string{CaseSmart, CaseIgnore, CaseRespect}
+
+// Options configures a Matcher
+type Options struct {
+	Algorithm string // AlgorithmGreedy (default) or AlgorithmOptimal
+	Case      string // CaseSmart (default), CaseIgnore or CaseRespect
+}
+
+// caseMode returns the case mode, CaseSmart when none is set
+func (o Options) caseMode() string {
+	if o.Case == "" {
+		return CaseSmart
+	}
+	return o.Case
 }
 
 // Entry is an internal representation for matching
 type Entry struct {
 	Data      Item
+	Index     int // position in the items passed to New
 	Text      string
 	TextLower string
 	BaseScore float64
-	TextRunes []rune
+	TextRunes []rune // Text folded and lowercased, without combining marks
+	Runes     []rune // TextRunes before lowercasing
+
+	// runeIndex maps TextRunes indices to rune indices of Text when
+	// combining marks were dropped; nil when they are the same
+	runeIndex []int
 }
 
-// Matcher performs fuzzy matching on a set of entries
+// Matcher performs fuzzy matching on a set of entries. It remembers which
+// entries matched the last query, so a query that extends it only rescans
+// those. Large sets are matched by several goroutines.
 type Matcher struct {
 	Entries []Entry
+	Options Options
+	// Workers caps the goroutines used per match; 0 means GOMAXPROCS
+	Workers int
+
+	mu            sync.Mutex
+	lastQuery     []rune
+	lastSensitive bool  // whether lastQuery was matched case-sensitively
+	lastHits      []int // indices into Entries that matched lastQuery
 }
 
+// parallelThreshold is the candidate count below which matching stays on
+// the calling goroutine; smaller sets finish faster than goroutines start
+const parallelThreshold = 4096
+
+// cancelCheckInterval is how many entries a worker matches between checks
+// for cancellation
+const cancelCheckInterval = 512
+
 // Match represents a successful fuzzy match
 type Match struct {
 	Entry     Item
+	Index     int // position of Entry in the items passed to New
 	Positions []int
 	Score     float64
 }
 
 // New creates a new fuzzy matcher
-func New(items []Item) *Matcher {
+func New(items []Item, opts Options) *Matcher {
 	entries := make([]Entry, 0, len(items))
-	for _, item := range items {
+	for i, item := range items {
+		runes, lower, index := fold(item.Text)
 		entries = append(entries, Entry{
 			Data:      item,
+			Index:     i,
 			Text:      item.Text,
 			TextLower: strings.ToLower(item.Text),
 			BaseScore: item.BaseScore,
-			TextRunes: []rune(strings.ToLower(item.Text)),
+			TextRunes: lower,
+			Runes:     runes,
+			runeIndex: index,
 		})
 	}
-	return &Matcher{Entries: entries}
+	return &Matcher{Entries: entries, Options: opts}
 }
 
 // Match finds all entries matching the query
 func (m *Matcher) Match(query string) []Match {
-	results := make([]Match, 0, len(m.Entries))
-	for _, entry := range m.Entries {
-		score, positions, ok := calculateMatch(entry, query)
-		if !ok {
-			continue
-		}
-		results = append(results, Match{
-			Entry:     entry.Data,
-			Positions: positions,
-			Score:     score,
-		})
+	matches, _ := m.MatchContext(context.Background(), query)
+	return matches
+}
+
+// MatchContext is Match that gives up when ctx is done, returning ctx's
+// error. A cancelled match leaves the remembered result set untouched.
+func (m *Matcher) MatchContext(ctx context.Context, query string) ([]Match, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q := parseQuery(query, m.Options.caseMode())
+	candidates := m.candidates(q)
+
+	var hits []int
+	var results []Match
+	workers := m.workers(len(candidates))
+	if workers <= 1 {
+		hits, results = m.matchRange(ctx, candidates, q)
+	} else {
+		hits, results = m.matchParallel(ctx, candidates, q, workers)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.lastQuery = q.plain
+	m.lastSensitive = q.sensitive
+	m.lastHits = hits
+	if q.plain == nil {
+		m.lastHits = nil
 	}
+	m.mu.Unlock()
 
 	sortByScore(results)
-	return results
+	return results, nil
 }
 
-func sortByScore(matches []Match) {
-	for i := 0; i < len(matches); i++ {
-		for j := i + 1; j < len(matches); j++ {
-			if matches[j].Score > matches[i].Score {
-				matches[i], matches[j] = matches[j], matches[i]
+// candidates returns the indices of entries that can match query: only the
+// previous hits when query extends the previous query, since an entry that
+// lacks a subsequence lacks every longer one too. A case-sensitive query
+// may narrow a case-insensitive one but not the reverse. nil means all
+// entries; queries using the extended syntax always scan them all.
+func (m *Matcher) candidates(q query) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.lastQuery
+	if q.plain == nil || m.lastHits == nil || m.lastSensitive && !q.sensitive ||
+		len(q.plain) < len(prev) || !slices.Equal(q.plain[:len(prev)], prev) {
+		return nil
+	}
+	return m.lastHits
+}
+
+func (m *Matcher) workers(candidates int) int {
+	if candidates < 0 {
+		candidates = 0
+	}
+	n := m.Workers
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if limit := candidates / parallelThreshold; n > limit {
+		n = limit
+	}
+	return n
+}
+
+// matchParallel splits the candidates into one contiguous chunk per worker
+// and joins the chunks in order, so results match a serial run
+func (m *Matcher) matchParallel(ctx context.Context, candidates []int, q query, workers int) ([]int, []Match) {
+	total := len(candidates)
+	if candidates == nil {
+		total = len(m.Entries)
+	}
+
+	hitParts := make([][]int, workers)
+	matchParts := make([][]Match, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*total/workers, (w+1)*total/workers
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			if candidates == nil {
+				hitParts[w], matchParts[w] = m.matchSpan(ctx, start, end, q)
+			} else {
+				hitParts[w], matchParts[w] = m.matchRange(ctx, candidates[start:end], q)
 			}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	hits := make([]int, 0, total)
+	results := make([]Match, 0, total)
+	for w := range hitParts {
+		hits = append(hits, hitParts[w]...)
+		results = append(results, matchParts[w]...)
+	}
+	return hits, results
+}
+
+// matchRange matches the entries at the given indices, or all entries
+// when indices is nil
+func (m *Matcher) matchRange(ctx context.Context, indices []int, q query) ([]int, []Match) {
+	if indices == nil {
+		return m.matchSpan(ctx, 0, len(m.Entries), q)
+	}
+	var sc scratch
+	hits := make([]int, 0, len(indices))
+	results := make([]Match, 0, len(indices))
+	for n, i := range indices {
+		if n%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, nil
+		}
+		if match, ok := m.matchEntry(&m.Entries[i], q, &sc); ok {
+			hits = append(hits, i)
+			results = append(results, match)
 		}
 	}
+	return hits, results
 }
 
-func calculateMatch(entry Entry, query string) (float64, []int, bool) {
-	positions := []int{}
-	score := entry.BaseScore
-	if query == "" {
-		return score, positions, true
+// matchSpan matches Entries[start:end]
+func (m *Matcher) matchSpan(ctx context.Context, start, end int, q query) ([]int, []Match) {
+	var sc scratch
+	hits := make([]int, 0, end-start)
+	results := make([]Match, 0, end-start)
+	for i := start; i < end; i++ {
+		if (i-start)%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, nil
+		}
+		if match, ok := m.matchEntry(&m.Entries[i], q, &sc); ok {
+			hits = append(hits, i)
+			results = append(results, match)
+		}
 	}
+	return hits, results
+}
 
-	queryLower := strings.ToLower(query)
-	queryRunes := []rune(queryLower)
-	textRunes := entry.TextRunes
+func (m *Matcher) matchEntry(entry *Entry, q query, sc *scratch) (Match, bool) {
+	score, positions, ok := m.matchQuery(entry, q, sc)
+	if !ok {
+		return Match{}, false
+	}
+	if entry.runeIndex != nil {
+		for i, p := range positions {
+			positions[i] = entry.runeIndex[p]
+		}
+	}
+	return Match{
+		Entry:     entry.Data,
+		Index:     entry.Index,
+		Positions: positions,
+		Score:     score,
+	}, true
+}
+
+// sortByScore orders matches by score, then by modification time (newest
+// first), then by text. The input position settles anything left, which
+// makes the order total and the result the same as a stable sort.
+func sortByScore(matches []Match) {
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Score != b.Score {
+			return cmp.Compare(b.Score, a.Score)
+		}
+		if c := b.Entry.Mtime.Compare(a.Entry.Mtime); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Entry.Text, b.Entry.Text); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Index, b.Index)
+	})
+}
+
+// calculateMatch takes the first occurrence of each query rune in turn in
+// textRunes, which is entry.TextRunes or, to respect case, entry.Runes
+func calculateMatch(entry *Entry, textRunes, queryRunes []rune, upper bool) (float64, []int, bool) {
+	positions := []int{}
+	if len(queryRunes) == 0 {
+		return entry.BaseScore, positions, true
+	}
 
-	lastPos := -1
 	pos := 0
 	for _, qc := range queryRunes {
 		found := -1
@@ -103,11 +331,36 @@ func calculateMatch(entry Entry, query string) (float64, []int, bool) {
 			return 0, nil, false
 		}
 		positions = append(positions, found)
+		pos = found + 1
+	}
+
+	return scorePositions(entry, positions, upper), positions, true
+}
+
+// scoreUpper is what matching an uppercase letter adds in smart case
+const scoreUpper = 0.5
+
+// scorePositions scores matched runes at ascending positions of entry:
+// a point per rune and per word start, a bonus that shrinks with each gap,
+// scaled down by how far into the text the match ends and by its length.
+// With upper, uppercase letters earn scoreUpper.
+func scorePositions(entry *Entry, positions []int, upper bool) float64 {
+	score := entry.BaseScore
+	if len(positions) == 0 {
+		return score
+	}
+
+	textRunes := entry.TextRunes
+	lastPos := -1
+	for _, found := range positions {
 		score += 1.0
 
 		if found == 0 || !isAlphaNum(textRunes[found-1]) {
 			score += 1.0
 		}
+		if upper && unicode.IsUpper(entry.Runes[found]) {
+			score += scoreUpper
+		}
 
 		if lastPos >= 0 {
 			gap := found - lastPos - 1
@@ -119,15 +372,11 @@ func calculateMatch(entry Entry, query string) (float64, []int, bool) {
 		}
 
 		lastPos = found
-		pos = found + 1
-	}
-
-	if lastPos >= 0 {
-		score *= float64(len(queryRunes)) / float64(lastPos+1)
 	}
-	score *= 10.0 / (float64(len([]rune(entry.Text))) + 10.0)
 
-	return score, positions, true
+	score *= float64(len(positions)) / float64(lastPos+1)
+	score *= 10.0 / (float64(utf8.RuneCountInString(entry.Text)) + 10.0)
+	return score
 }
 
 var sqrtTable = func() []float64 {
@@ -138,6 +387,40 @@ var sqrtTable = func() []float64 {
 	return table
 }()
 
+// isAlphaNum reports whether r is a word character of lowercased text
 func isAlphaNum(r rune) bool {
-	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+	if r < utf8.RuneSelf {
+		return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fold prepares text for matching: letters with diacritics become their
+// base letter (é and e\u0301 both become e) and combining marks are
+// dropped. It returns the folded runes, their lowercase, and for each
+// folded rune its index in text, or nil when no rune was dropped.
+func fold(text string) (runes, lower []rune, index []int) {
+	runes = make([]rune, 0, len(text))
+	lower = make([]rune, 0, len(text))
+	i := 0
+	for _, r := range text {
+		if r >= utf8.RuneSelf && unicode.Is(unicode.Mn, r) {
+			if index == nil {
+				index = make([]int, len(runes), len(text))
+				for j := range index {
+					index[j] = j
+				}
+			}
+			i++
+			continue
+		}
+		r = slug.FoldRune(r)
+		runes = append(runes, r)
+		lower = append(lower, unicode.ToLower(r))
+		if index != nil {
+			index = append(index, i)
+		}
+		i++
+	}
+	return runes, lower, index
 }
@@ -0,0 +1,125 @@
+package fuzzy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var benchWords = []string{
+	"api", "auth", "bench", "cache", "cli", "client", "config", "data",
+	"demo", "experiment", "fuzzy", "graph", "http", "index", "parser",
+	"proto", "queue", "redis", "rust", "server", "sketch", "spike", "test",
+	"tui", "web", "worker",
+}
+
+// benchItems returns n tries named like real ones, with a fixed seed so
+// every run matches the same set
+func benchItems(n int) []Item {
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := make([]Item, n)
+	for i := range items {
+		mtime := start.Add(time.Duration(rng.Intn(5*365*24)) * time.Hour)
+		name := fmt.Sprintf("%s-%s-%s-%d", mtime.Format("2006-01-02"),
+			benchWords[rng.Intn(len(benchWords))], benchWords[rng.Intn(len(benchWords))], i)
+		items[i] = Item{
+			Text:      name,
+			Path:      "/tries/" + name,
+			BaseScore: rng.Float64() * 5,
+			Mtime:     mtime,
+		}
+	}
+	return items
+}
+
+// benchmarkMatch matches query against n items with a fresh Matcher each
+// time, so every match is a full scan rather than an incremental one
+func benchmarkMatch(b *testing.B, n int, query, algorithm string) {
+	items := benchItems(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := New(items, Options{Algorithm: algorithm})
+		b.StartTimer()
+		m.Match(query)
+	}
+}
+
+func BenchmarkMatchEmpty10k(b *testing.B)   { benchmarkMatch(b, 10_000, "", AlgorithmGreedy) }
+func BenchmarkMatchShort10k(b *testing.B)   { benchmarkMatch(b, 10_000, "a", AlgorithmGreedy) }
+func BenchmarkMatchWord10k(b *testing.B)    { benchmarkMatch(b, 10_000, "cache", AlgorithmGreedy) }
+func BenchmarkMatchSparse10k(b *testing.B)  { benchmarkMatch(b, 10_000, "tsrvr", AlgorithmGreedy) }
+func BenchmarkMatchEmpty100k(b *testing.B)  { benchmarkMatch(b, 100_000, "", AlgorithmGreedy) }
+func BenchmarkMatchShort100k(b *testing.B)  { benchmarkMatch(b, 100_000, "a", AlgorithmGreedy) }
+func BenchmarkMatchWord100k(b *testing.B)   { benchmarkMatch(b, 100_000, "cache", AlgorithmGreedy) }
+func BenchmarkMatchSparse100k(b *testing.B) { benchmarkMatch(b, 100_000, "tsrvr", AlgorithmGreedy) }
+
+func BenchmarkOptimalShort10k(b *testing.B)   { benchmarkMatch(b, 10_000, "a", AlgorithmOptimal) }
+func BenchmarkOptimalWord10k(b *testing.B)    { benchmarkMatch(b, 10_000, "cache", AlgorithmOptimal) }
+func BenchmarkOptimalSparse10k(b *testing.B)  { benchmarkMatch(b, 10_000, "tsrvr", AlgorithmOptimal) }
+func BenchmarkOptimalShort100k(b *testing.B)  { benchmarkMatch(b, 100_000, "a", AlgorithmOptimal) }
+func BenchmarkOptimalWord100k(b *testing.B)   { benchmarkMatch(b, 100_000, "cache", AlgorithmOptimal) }
+func BenchmarkOptimalSparse100k(b *testing.B) { benchmarkMatch(b, 100_000, "tsrvr", AlgorithmOptimal) }
+
+func BenchmarkNew100k(b *testing.B) {
+	items := benchItems(100_000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New(items, Options{})
+	}
+}
+
+// benchmarkTyping matches each prefix of query in turn, as typing it does
+func benchmarkTyping(b *testing.B, n int, query string, workers int) {
+	items := benchItems(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := New(items, Options{})
+		m.Workers = workers
+		b.StartTimer()
+		for end := 1; end <= len(query); end++ {
+			m.Match(query[:end])
+		}
+	}
+}
+
+func BenchmarkTyping10k(b *testing.B)        { benchmarkTyping(b, 10_000, "cache", 0) }
+func BenchmarkTyping100k(b *testing.B)       { benchmarkTyping(b, 100_000, "cache", 0) }
+func BenchmarkTypingSerial100k(b *testing.B) { benchmarkTyping(b, 100_000, "cache", 1) }
+
+// cancelAfter is a context that reports cancellation once Err has been
+// called more than n times, standing in for a keystroke arriving mid-match
+type cancelAfter struct {
+	context.Context
+	n atomic.Int32
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n.Add(-1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// BenchmarkMatchCancelled100k measures how quickly a match for a stale
+// query gives up once it is cancelled
+func BenchmarkMatchCancelled100k(b *testing.B) {
+	m := New(benchItems(100_000), Options{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx := &cancelAfter{Context: context.Background()}
+		ctx.n.Store(2)
+		if _, err := m.MatchContext(ctx, "a"); err == nil {
+			b.Fatal("cancelled match returned results")
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package fuzzy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMatchTieOrder checks that equal scores are ordered by modification
+// time (newest first), then text, then input position
+func TestMatchTieOrder(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	items := []Item{
+		{Text: "b-try", BaseScore: 1, Mtime: older},
+		{Text: "a-try", BaseScore: 1, Mtime: older},
+		{Text: "c-try", BaseScore: 1, Mtime: newer},
+		{Text: "a-try", BaseScore: 1, Mtime: older},
+	}
+	want := []int{2, 1, 3, 0}
+
+	for _, query := range []string{"", "try"} {
+		for _, algorithm := range []string{AlgorithmGreedy, AlgorithmOptimal} {
+			matches := New(items, Options{Algorithm: algorithm}).Match(query)
+			if len(matches) != len(want) {
+				t.Fatalf("%s %q: got %d matches, want %d", algorithm, query, len(matches), len(want))
+			}
+			for i, m := range matches {
+				if m.Score != matches[0].Score {
+					t.Fatalf("%s %q: scores differ: %v and %v", algorithm, query, matches[0].Score, m.Score)
+				}
+				if m.Index != want[i] {
+					t.Errorf("%s %q: match %d is item %d, want %d", algorithm, query, i, m.Index, want[i])
+				}
+			}
+		}
+	}
+}
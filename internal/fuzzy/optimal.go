@@ -0,0 +1,210 @@
+package fuzzy
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scores of the optimal-alignment scorer, in the spirit of fzf's v2
+// algorithm. A matched character earns scoreMatch plus the bonus of its
+// position; gaps between matched characters cost a start and a per
+// character penalty.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// bonusBoundary rewards a match right after a separator such as - _ / .
+	bonusBoundary = 8
+	// bonusCamel rewards a match on a lower-to-upper case transition
+	bonusCamel = 7
+	// bonusConsecutive is the least a character continuing a run earns;
+	// runs also inherit the bonus of the character that started them
+	bonusConsecutive = 4
+	// bonusNameStart rewards matching the first character after the
+	// YYYY-MM-DD- date prefix
+	bonusNameStart = 10
+	// bonusFirstCharMultiplier weights the bonus of the first query rune
+	bonusFirstCharMultiplier = 2
+	// bonusUpper rewards matching an uppercase letter in smart case
+	bonusUpper = 2
+)
+
+const scoreNone = -1 << 30
+
+// scratch holds the dynamic programming tables of one goroutine, reused
+// across entries to keep matching allocation-free
+type scratch struct {
+	bonus []int32
+	score []int32 // best score with query[i] matched at text[j]
+	run   []int32 // bonus carried by the run ending at (i, j)
+	from  []int32 // text position of query[i-1] in that best alignment
+}
+
+func (sc *scratch) reset(n, m int) {
+	if cap(sc.bonus) < m {
+		sc.bonus = make([]int32, m)
+	}
+	if cap(sc.score) < n*m {
+		sc.score = make([]int32, n*m)
+		sc.run = make([]int32, n*m)
+		sc.from = make([]int32, n*m)
+	}
+	sc.bonus = sc.bonus[:m]
+	sc.score = sc.score[:n*m]
+	sc.run = sc.run[:n*m]
+	sc.from = sc.from[:n*m]
+}
+
+// optimalMatch finds the alignment of query in text, entry.TextRunes or
+// entry.Runes, with the highest score instead of taking the first
+// occurrence of each rune. It costs O(len(query) × len(text)) per entry
+// that contains the query.
+func optimalMatch(entry *Entry, text, query []rune, upper bool, sc *scratch) (float64, []int, bool) {
+	if len(query) == 0 {
+		return entry.BaseScore, []int{}, true
+	}
+	if !isSubsequence(query, text) {
+		return 0, nil, false
+	}
+
+	n, m := len(query), len(text)
+	sc.reset(n, m)
+	positionBonuses(entry, sc.bonus, upper)
+
+	for i := 0; i < n; i++ {
+		row := i * m
+		prev := row - m
+		carry, carryFrom := int32(scoreNone), int32(-1)
+		for j := 0; j < m; j++ {
+			// carry is the best score of query[i-1] at some k <= j-2,
+			// less the gap penalty for reaching j
+			if i > 0 && j >= 2 {
+				if carry != scoreNone {
+					carry += scoreGapExtension
+				}
+				if s := sc.score[prev+j-2]; s != scoreNone && s+scoreGapStart >= carry {
+					carry, carryFrom = s+scoreGapStart, int32(j-2)
+				}
+			}
+
+			idx := row + j
+			sc.score[idx] = scoreNone
+			if text[j] != query[i] {
+				continue
+			}
+			bonus := sc.bonus[j]
+			if i == 0 {
+				sc.score[idx] = scoreMatch + bonus*bonusFirstCharMultiplier
+				sc.run[idx] = bonus
+				sc.from[idx] = -1
+				continue
+			}
+
+			best := int32(scoreNone)
+			if j > 0 && sc.score[prev+j-1] != scoreNone {
+				run := max(sc.run[prev+j-1], bonus, bonusConsecutive)
+				best = sc.score[prev+j-1] + scoreMatch + run
+				sc.run[idx] = run
+				sc.from[idx] = int32(j - 1)
+			}
+			if carry != scoreNone {
+				if s := carry + scoreMatch + bonus; s > best {
+					best = s
+					sc.run[idx] = bonus
+					sc.from[idx] = carryFrom
+				}
+			}
+			sc.score[idx] = best
+		}
+	}
+
+	last := (n - 1) * m
+	bestScore, bestEnd := int32(scoreNone), -1
+	for j := 0; j < m; j++ {
+		if s := sc.score[last+j]; s > bestScore {
+			bestScore, bestEnd = s, j
+		}
+	}
+	if bestEnd < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, n)
+	for i, j := n-1, bestEnd; i >= 0; i-- {
+		positions[i] = j
+		j = int(sc.from[i*m+j])
+	}
+
+	score := entry.BaseScore + float64(bestScore)/scoreMatch
+	score *= 10.0 / (float64(utf8.RuneCountInString(entry.Text)) + 10.0)
+	return score, positions, true
+}
+
+// positionBonuses fills bonus with what matching each rune of entry is
+// worth. The nested year/month directories and the date prefix earn
+// nothing, so matches land in the name. With upper, uppercase letters
+// earn bonusUpper on top.
+func positionBonuses(entry *Entry, bonus []int32, upper bool) {
+	text := entry.TextRunes
+	original := entry.Runes
+	if len(original) != len(text) {
+		original = text // case folding changed the length; skip camelCase
+	}
+
+	nameStart := 0
+	for j, r := range text {
+		if r == '/' {
+			nameStart = j + 1
+		}
+	}
+	dateEnd := nameStart
+	if hasDatePrefix(text[nameStart:]) {
+		dateEnd = nameStart + len("2006-01-02-")
+	}
+
+	for j := range text {
+		switch {
+		case j < dateEnd:
+			bonus[j] = 0
+		case j == dateEnd && dateEnd > nameStart:
+			bonus[j] = bonusNameStart
+		case j == 0 || !isAlphaNum(text[j-1]):
+			bonus[j] = bonusBoundary
+		case unicode.IsLower(original[j-1]) && unicode.IsUpper(original[j]):
+			bonus[j] = bonusCamel
+		default:
+			bonus[j] = 0
+		}
+		if upper && j >= dateEnd && unicode.IsUpper(original[j]) {
+			bonus[j] += bonusUpper
+		}
+	}
+}
+
+// hasDatePrefix reports whether name starts with YYYY-MM-DD-
+func hasDatePrefix(name []rune) bool {
+	const layout = "dddd-dd-dd-"
+	if len(name) <= len(layout) {
+		return false
+	}
+	for i, c := range layout {
+		if c == 'd' && (name[i] < '0' || name[i] > '9') || c == '-' && name[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubsequence(query, text []rune) bool {
+	i := 0
+	for _, r := range text {
+		if r == query[i] {
+			i++
+			if i == len(query) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package fuzzy
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// termKind is how a query term matches
+type termKind int
+
+const (
+	termFuzzy  termKind = iota // abc: characters in order
+	termExact                  // 'abc: substring
+	termPrefix                 // ^abc: start of the text or of the name
+	termSuffix                 // abc$: end of the text
+	termEqual                  // ^abc$: the whole text or the whole name
+)
+
+// term is one word of an extended query
+type term struct {
+	kind      termKind
+	text      []rune
+	inverse   bool // !abc: the entry must not match
+	sensitive bool // match case exactly
+}
+
+// query is a parsed extended query: every group must match, and a group
+// matches when any of its terms does
+type query struct {
+	groups [][]term
+	// plain is the query when it is a single fuzzy term, the only form
+	// whose matches can be narrowed as the query grows
+	plain     []rune
+	sensitive bool // plain is matched case-sensitively
+}
+
+// parseQuery folds input like entry text and splits it into fzf-style
+// terms, lowercasing those caseMode matches regardless of case. Spaces
+// separate terms
+// that must all match ("\ " is a literal space), "|" between terms means
+// either may match, and each term may carry operators:
+//
+//	abc    fuzzy        'abc   exact substring
+//	^abc   prefix       abc$   suffix        ^abc$  equal
+//	!abc   must not contain abc (also !^abc, !abc$, !'abc)
+func parseQuery(input, caseMode string) query {
+	var q query
+	var group []term
+	orNext := false
+	folded, _, _ := fold(input)
+	for _, word := range splitQuery(string(folded)) {
+		if word == "|" {
+			orNext = len(group) > 0
+			continue
+		}
+		sensitive := caseMode == CaseRespect || caseMode == CaseSmart && hasUpper(word)
+		if !sensitive {
+			word = strings.ToLower(word)
+		}
+		t, ok := parseTerm(word)
+		t.sensitive = sensitive
+		if !ok {
+			continue
+		}
+		if orNext {
+			group = append(group, t)
+		} else {
+			if group != nil {
+				q.groups = append(q.groups, group)
+			}
+			group = []term{t}
+		}
+		orNext = false
+	}
+	if group != nil {
+		q.groups = append(q.groups, group)
+	}
+
+	if len(q.groups) == 0 {
+		q.plain = []rune{}
+	}
+	if len(q.groups) == 1 && len(q.groups[0]) == 1 {
+		if t := q.groups[0][0]; t.kind == termFuzzy && !t.inverse {
+			q.plain = t.text
+			q.sensitive = t.sensitive
+		}
+	}
+	return q
+}
+
+// splitQuery splits on unescaped spaces
+func splitQuery(input string) []string {
+	words := []string{}
+	var word strings.Builder
+	escaped := false
+	for _, r := range input {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ' ':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if escaped {
+		word.WriteRune('\\')
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+func hasUpper(word string) bool {
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTerm(word string) (term, bool) {
+	t := term{kind: termFuzzy}
+	if rest, ok := strings.CutPrefix(word, "!"); ok {
+		t.inverse = true
+		t.kind = termExact
+		word = rest
+	}
+	switch {
+	case strings.HasPrefix(word, "'"):
+		t.kind = termExact
+		word = word[1:]
+	case strings.HasPrefix(word, "^") && strings.HasSuffix(word, "$") && len(word) > 1:
+		t.kind = termEqual
+		word = word[1 : len(word)-1]
+	case strings.HasPrefix(word, "^"):
+		t.kind = termPrefix
+		word = word[1:]
+	case strings.HasSuffix(word, "$"):
+		t.kind = termSuffix
+		word = word[:len(word)-1]
+	}
+	if word == "" {
+		return t, false
+	}
+	t.text = []rune(word)
+	return t, true
+}
+
+// matchQuery matches entry against every group of q. The score is the mean
+// of the group scores, so it stays comparable with single-term queries;
+// positions are the union of those of the matching terms.
+func (m *Matcher) matchQuery(entry *Entry, q query, sc *scratch) (float64, []int, bool) {
+	if q.plain != nil {
+		return m.matchFuzzy(entry, q.plain, q.sensitive, sc)
+	}
+
+	total, scored := 0.0, 0
+	var positions []int
+	for _, group := range q.groups {
+		matched := false
+		best := 0.0
+		var bestPositions []int
+		for _, t := range group {
+			score, pos, ok := m.matchTerm(entry, t, sc)
+			if !ok {
+				continue
+			}
+			if !matched || score > best {
+				best, bestPositions = score, pos
+			}
+			matched = true
+		}
+		if !matched {
+			return 0, nil, false
+		}
+		if bestPositions != nil {
+			total += best
+			scored++
+			positions = append(positions, bestPositions...)
+		}
+	}
+
+	if scored == 0 {
+		return entry.BaseScore, []int{}, true
+	}
+	slices.Sort(positions)
+	return total / float64(scored), slices.Compact(positions), true
+}
+
+// matchTerm matches one term. Inverse terms report no positions.
+func (m *Matcher) matchTerm(entry *Entry, t term, sc *scratch) (float64, []int, bool) {
+	text := entry.TextRunes
+	if t.sensitive {
+		text = entry.Runes
+	}
+	var positions []int
+	ok := false
+	switch t.kind {
+	case termFuzzy:
+		return m.matchFuzzy(entry, t.text, t.sensitive, sc)
+	case termExact:
+		positions, ok = findExact(text, t.text)
+	case termPrefix:
+		positions, ok = findPrefix(text, t.text)
+	case termSuffix:
+		positions, ok = findSuffix(text, t.text)
+	case termEqual:
+		positions, ok = findEqual(text, t.text)
+	}
+	if t.inverse {
+		return 0, nil, !ok
+	}
+	if !ok {
+		return 0, nil, false
+	}
+	return scorePositions(entry, positions, m.Options.caseMode() == CaseSmart), positions, true
+}
+
+func (m *Matcher) matchFuzzy(entry *Entry, query []rune, sensitive bool, sc *scratch) (float64, []int, bool) {
+	text := entry.TextRunes
+	if sensitive {
+		text = entry.Runes
+	}
+	upper := m.Options.caseMode() == CaseSmart
+	if m.Options.Algorithm == AlgorithmOptimal {
+		return optimalMatch(entry, text, query, upper, sc)
+	}
+	return calculateMatch(entry, text, query, upper)
+}
+
+func findExact(text, sub []rune) ([]int, bool) {
+	for start := 0; start+len(sub) <= len(text); start++ {
+		if slices.Equal(text[start:start+len(sub)], sub) {
+			return span(start, len(sub)), true
+		}
+	}
+	return nil, false
+}
+
+// findPrefix matches at the start of the text, of the name below nested
+// directories, or of the name after its date prefix
+func findPrefix(text, sub []rune) ([]int, bool) {
+	nameStart, dateEnd := nameBounds(text)
+	for _, start := range []int{0, nameStart, dateEnd} {
+		if start+len(sub) <= len(text) && slices.Equal(text[start:start+len(sub)], sub) {
+			return span(start, len(sub)), true
+		}
+	}
+	return nil, false
+}
+
+func findSuffix(text, sub []rune) ([]int, bool) {
+	start := len(text) - len(sub)
+	if start >= 0 && slices.Equal(text[start:], sub) {
+		return span(start, len(sub)), true
+	}
+	return nil, false
+}
+
+// findEqual matches the whole text, the whole name, or the name after its
+// date prefix
+func findEqual(text, sub []rune) ([]int, bool) {
+	nameStart, dateEnd := nameBounds(text)
+	for _, start := range []int{0, nameStart, dateEnd} {
+		if slices.Equal(text[start:], sub) {
+			return span(start, len(sub)), true
+		}
+	}
+	return nil, false
+}
+
+// nameBounds returns where the name starts below any nested directories,
+// and where it starts after its YYYY-MM-DD- prefix (nameStart if none)
+func nameBounds(text []rune) (nameStart, dateEnd int) {
+	for j, r := range text {
+		if r == '/' {
+			nameStart = j + 1
+		}
+	}
+	dateEnd = nameStart
+	if hasDatePrefix(text[nameStart:]) {
+		dateEnd = nameStart + len("2006-01-02-")
+	}
+	return nameStart, dateEnd
+}
+
+func span(start, n int) []int {
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = start + i
+	}
+	return positions
+}
@@ -0,0 +1,270 @@
+// Package grep searches inside tries: the names of their files, their
+// notes and, optionally, the contents of every text file
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Options controls what Search reads
+type Options struct {
+	// Contents searches every text file; otherwise only file names and
+	// notes (README, NOTES, TODO, *.md, *.txt, *.org) are searched
+	Contents bool
+	// Workers caps the tries searched at once; 0 means GOMAXPROCS
+	Workers int
+}
+
+// maxFileSize is the largest file whose contents are searched
+const maxFileSize = 1 << 20
+
+// maxSnippet is the most bytes of a matching line kept for the preview
+const maxSnippet = 200
+
+// Hit is one match inside a try
+type Hit struct {
+	File  string // relative to the try
+	Line  int    // 1-based; 0 when the file name matched
+	Text  string // the matching line, or the file path
+	Start int    // byte range of the match in Text; Start == End if unknown
+	End   int
+}
+
+// Result is what a try yielded
+type Result struct {
+	Index int    // position of the try in the dirs passed to Search
+	Path  string // the try directory
+	Hits  int    // matching file names plus matching lines
+	First Hit    // the first hit, for the preview
+}
+
+// Search looks for pattern in each of dirs and returns the tries with
+// hits, most hits first. Pattern is a literal; it is case-sensitive only
+// if it has an uppercase letter. Directories named .git or node_modules
+// and paths matched by .gitignore files are skipped.
+func Search(ctx context.Context, dirs []string, pattern string, opts Options) ([]Result, error) {
+	if pattern == "" {
+		return []Result{}, nil
+	}
+	m := newMatcher(pattern)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(dirs) {
+		workers = len(dirs)
+	}
+
+	results := make([]Result, len(dirs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = searchTry(ctx, dirs[i], m, opts)
+				results[i].Index = i
+			}
+		}()
+	}
+	for i := range dirs {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	found := make([]Result, 0, len(results))
+	for _, r := range results {
+		if r.Hits > 0 {
+			found = append(found, r)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Hits > found[j].Hits
+	})
+	return found, nil
+}
+
+// searchTry walks one try, skipping ignored paths
+func searchTry(ctx context.Context, dir string, m matcher, opts Options) Result {
+	result := Result{Path: dir}
+	record := func(hit Hit) {
+		if result.Hits == 0 {
+			result.First = hit
+		}
+		result.Hits++
+	}
+
+	ign := &ignorer{}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if rel == "." {
+			ign.load(path, "")
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if defaultIgnored[d.Name()] || ign.ignored(rel, true) {
+				return filepath.SkipDir
+			}
+			ign.load(path, rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || ign.ignored(rel, false) {
+			return nil
+		}
+
+		if start, end, ok := m.find(d.Name()); ok {
+			offset := len(rel) - len(d.Name())
+			record(Hit{File: rel, Text: rel, Start: start + offset, End: end + offset})
+		}
+		if opts.Contents || isNote(d.Name()) {
+			searchFile(path, rel, m, record)
+		}
+		return nil
+	})
+	return result
+}
+
+// defaultIgnored are directories never searched
+var defaultIgnored = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+// isNote reports whether a file is read even without Options.Contents
+func isNote(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range []string{"readme", "notes", "todo"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	switch filepath.Ext(lower) {
+	case ".md", ".txt", ".org":
+		return true
+	}
+	return false
+}
+
+// searchFile records a hit per matching line of a text file
+func searchFile(path, rel string, m matcher, record func(Hit)) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFileSize {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || isBinary(data) {
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		start, end, ok := m.find(text)
+		if !ok {
+			continue
+		}
+		snippet, start, end := trimSnippet(text, start, end)
+		record(Hit{File: rel, Line: line, Text: snippet, Start: start, End: end})
+	}
+}
+
+// isBinary reports whether data looks like a binary file: a NUL byte in
+// its first 8000 bytes, as git decides
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// trimSnippet strips indentation and cuts long lines to maxSnippet bytes
+// around the match, shifting the match range to the result
+func trimSnippet(text string, start, end int) (string, int, int) {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	shift := len(text) - len(trimmed)
+	text = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if start == end {
+		start, end = shift, shift // unknown; keep it empty after shifting
+	}
+	end = max(0, min(end-shift, len(text)))
+	start = min(max(0, start-shift), end)
+	if len(text) <= maxSnippet {
+		return text, start, end
+	}
+	from := max(0, min(start-maxSnippet/4, len(text)-maxSnippet))
+	for from > 0 && !startsRune(text[from]) {
+		from--
+	}
+	to := min(len(text), from+maxSnippet)
+	for to < len(text) && !startsRune(text[to]) {
+		to--
+	}
+	start, end = max(0, start-from), max(0, min(to, end)-from)
+	return text[from:to], min(start, end), end
+}
+
+func startsRune(b byte) bool {
+	return b&0xc0 != 0x80
+}
+
+// matcher finds a literal, ignoring case unless it has an uppercase letter
+type matcher struct {
+	pattern   string
+	sensitive bool
+}
+
+func newMatcher(pattern string) matcher {
+	sensitive := strings.IndexFunc(pattern, unicode.IsUpper) >= 0
+	if !sensitive {
+		pattern = strings.ToLower(pattern)
+	}
+	return matcher{pattern: pattern, sensitive: sensitive}
+}
+
+// find returns the byte range of the first match in text. When lowercasing
+// changed the byte length of text, the range is empty.
+func (m matcher) find(text string) (int, int, bool) {
+	if m.sensitive {
+		i := strings.Index(text, m.pattern)
+		return i, i + len(m.pattern), i >= 0
+	}
+	lower := strings.ToLower(text)
+	i := strings.Index(lower, m.pattern)
+	if i < 0 {
+		return 0, 0, false
+	}
+	if len(lower) != len(text) {
+		return 0, 0, true
+	}
+	return i, i + len(m.pattern), true
+}
@@ -0,0 +1,110 @@
+package grep
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern of a .gitignore file
+type ignoreRule struct {
+	base     string // directory of the .gitignore, relative to the try
+	pattern  string // without the leading '!' and '/' or the trailing '/'
+	negate   bool   // "!pattern" re-includes what an earlier rule ignored
+	dirOnly  bool   // "pattern/" only matches directories
+	anchored bool   // a '/' before the end ties the pattern to base
+}
+
+// ignorer holds the .gitignore rules of the directories walked so far.
+// Rules of a directory only apply below it, and later rules win.
+type ignorer struct {
+	rules []ignoreRule
+}
+
+// load reads the .gitignore of dir, whose path relative to the try is rel
+func (ig *ignorer) load(dir, rel string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: rel}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			rule.negate = true
+			line = rest
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			rule.dirOnly = true
+			line = rest
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		ig.rules = append(ig.rules, rule)
+	}
+}
+
+// ignored reports whether the path rel, relative to the try, is ignored
+func (ig *ignorer) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if rule.base != "" {
+			var ok bool
+			if sub, ok = strings.CutPrefix(rel, rule.base+"/"); !ok {
+				continue
+			}
+		}
+		var matched bool
+		if rule.anchored {
+			matched = matchSegments(strings.Split(rule.pattern, "/"), strings.Split(sub, "/"))
+		} else {
+			matched, _ = path.Match(rule.pattern, path.Base(sub))
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches a path against a pattern segment by segment, where
+// a "**" segment matches any number of path segments
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(parts); skip++ {
+				if matchSegments(pattern[1:], parts[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,292 @@
+// Package index keeps a snapshot of the tries under a root on disk, so the
+// selector can draw its first frame without scanning the root
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// header starts every index file; a file without it is ignored
+const header = "# try index v2"
+
+// SizeTTL is how long a measured size is trusted. A try's modification
+// time only changes with its direct children, so files growing deeper in
+// it go unnoticed until the size expires.
+const SizeTTL = time.Hour
+
+// Index is the set of tries under a root, with the modification times of
+// the directories listed to find them
+type Index struct {
+	path  string
+	depth int
+	// Dirs maps each listed directory, relative to the root ("" for the
+	// root itself), to its modification time when it was listed
+	Dirs  map[string]time.Time
+	Tries []Entry
+}
+
+// Entry is one try
+type Entry struct {
+	Path     string // relative to the root, e.g. "2025/01/2025-01-02-name"
+	Mtime    time.Time
+	Size     int64     // disk usage in bytes, -1 until measured
+	Measured time.Time // when Size was measured
+}
+
+// SizeFresh reports whether the entry has a size measured within SizeTTL
+func (e Entry) SizeFresh(now time.Time) bool {
+	return e.Size >= 0 && now.Sub(e.Measured) < SizeTTL
+}
+
+// File returns where the index of the tries under root is kept
+func File(root string) string {
+	return filepath.Join(root, ".try", "index")
+}
+
+// Load reads the index at path, which was scanned to depth. It fails when
+// the file is missing, unreadable or was written for another depth.
+func Load(path string, depth int) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ix := &Index{path: path, depth: depth, Dirs: map[string]time.Time{}}
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != fmt.Sprintf("%s depth=%d", header, depth) {
+		return nil, fmt.Errorf("%s: not an index for depth %d", path, depth)
+	}
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		switch {
+		case len(fields) == 3 && fields[0] == "d":
+			ix.Dirs[fromSlot(fields[1])] = parseTime(fields[2])
+		case len(fields) == 5 && fields[0] == "t":
+			size, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				size = -1
+			}
+			ix.Tries = append(ix.Tries, Entry{Path: fields[1], Mtime: parseTime(fields[2]), Size: size, Measured: parseTime(fields[4])})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Fresh reports whether every directory listed for the index still has the
+// same modification time, so no try was added, removed or renamed since
+func (ix *Index) Fresh(root string) bool {
+	if len(ix.Dirs) == 0 {
+		return false
+	}
+	for rel, mtime := range ix.Dirs {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil || !info.ModTime().Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Save writes the index atomically
+func (ix *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s depth=%d\n", header, ix.depth)
+	dirs := make([]string, 0, len(ix.Dirs))
+	for rel := range ix.Dirs {
+		dirs = append(dirs, rel)
+	}
+	sort.Strings(dirs)
+	for _, rel := range dirs {
+		fmt.Fprintf(&b, "d\t%s\t%d\n", toSlot(rel), formatTime(ix.Dirs[rel]))
+	}
+	for _, t := range ix.Tries {
+		if strings.ContainsAny(t.Path, "\t\n") {
+			return fmt.Errorf("cannot index %q", t.Path)
+		}
+		fmt.Fprintf(&b, "t\t%s\t%d\t%d\t%d\n", t.Path, formatTime(t.Mtime), t.Size, formatTime(t.Measured))
+	}
+
+	// Several selectors may save at once; each writes its own file
+	tmp, err := os.CreateTemp(filepath.Dir(ix.path), ".index-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), ix.path)
+}
+
+// Scan lists the tries under root, descending into YYYY and YYYY/MM
+// buckets while above depth. Hidden entries and files are skipped.
+func Scan(root string, depth int) *Index {
+	ix := &Index{path: File(root), depth: depth, Dirs: map[string]time.Time{}}
+	ix.scan(root, "", 1)
+	return ix
+}
+
+func (ix *Index) scan(dir, relDir string, level int) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	ix.Dirs[relDir] = info.ModTime()
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || !entry.IsDir() {
+			continue
+		}
+		relPath := name
+		if relDir != "" {
+			relPath = relDir + "/" + name
+		}
+		if level < ix.depth && IsBucketDir(name, level) {
+			ix.scan(filepath.Join(dir, name), relPath, level+1)
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		ix.Tries = append(ix.Tries, Entry{Path: relPath, Mtime: info.ModTime(), Size: -1})
+	}
+}
+
+// KeepSizes copies the sizes measured in old to tries that have not been
+// modified since, until the sizes expire
+func (ix *Index) KeepSizes(old map[string]Entry) {
+	now := time.Now()
+	for i, t := range ix.Tries {
+		if o, ok := old[t.Path]; ok && o.Mtime.Equal(t.Mtime) && o.SizeFresh(now) {
+			ix.Tries[i].Size, ix.Tries[i].Measured = o.Size, o.Measured
+		}
+	}
+}
+
+// ByPath returns a copy of the entries keyed by path
+func (ix *Index) ByPath() map[string]Entry {
+	m := make(map[string]Entry, len(ix.Tries))
+	for _, t := range ix.Tries {
+		m[t.Path] = t
+	}
+	return m
+}
+
+// SetSizes records sizes measured now, keyed by path
+func (ix *Index) SetSizes(sizes map[string]int64) {
+	now := time.Now()
+	for i, t := range ix.Tries {
+		if size, ok := sizes[t.Path]; ok {
+			ix.Tries[i].Size, ix.Tries[i].Measured = size, now
+		}
+	}
+}
+
+// Clone returns a copy that can be read while ix changes
+func (ix *Index) Clone() *Index {
+	c := &Index{path: ix.path, depth: ix.depth, Dirs: make(map[string]time.Time, len(ix.Dirs))}
+	for rel, mtime := range ix.Dirs {
+		c.Dirs[rel] = mtime
+	}
+	c.Tries = append([]Entry(nil), ix.Tries...)
+	return c
+}
+
+// Equal reports whether both indexes list the same tries and directories
+// with the same times and sizes
+func (ix *Index) Equal(other *Index) bool {
+	if len(ix.Tries) != len(other.Tries) || len(ix.Dirs) != len(other.Dirs) {
+		return false
+	}
+	for rel, mtime := range ix.Dirs {
+		if o, ok := other.Dirs[rel]; !ok || !o.Equal(mtime) {
+			return false
+		}
+	}
+	for i, t := range ix.Tries {
+		o := other.Tries[i]
+		if o.Path != t.Path || !o.Mtime.Equal(t.Mtime) || o.Size != t.Size || !o.Measured.Equal(t.Measured) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsBucketDir reports whether name is a year (level 1) or month (level 2)
+// directory of the nested layout
+func IsBucketDir(name string, level int) bool {
+	switch level {
+	case 1:
+		return len(name) == 4 && isDigits(name)
+	case 2:
+		return len(name) == 2 && isDigits(name)
+	}
+	return false
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+// The root is written as "." so no field is empty
+func toSlot(rel string) string {
+	if rel == "" {
+		return "."
+	}
+	return rel
+}
+
+func fromSlot(field string) string {
+	if field == "." {
+		return ""
+	}
+	return field
+}
+
+// Times are written in nanoseconds, with 0 for the zero time, which
+// UnixNano cannot represent
+func formatTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func parseTime(field string) time.Time {
+	n, err := strconv.ParseInt(field, 10, 64)
+	if err != nil || n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
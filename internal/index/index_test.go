@@ -0,0 +1,117 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadRejects checks that files not written for the requested depth
+// are refused, so the selector scans instead
+func TestLoadRejects(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"garbage", "\x00\x01not an index\n"},
+		{"no header", "t\tname\t1\t-1\t0\n"},
+		{"old version", "# try index v1 depth=1\n"},
+		{"other depth", "# try index v2 depth=3\n"},
+		{"no depth", "# try index v2\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path, 1); err == nil {
+			t.Errorf("%s: Load succeeded", tt.name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing"), 1); err == nil {
+		t.Error("missing: Load succeeded")
+	}
+}
+
+// TestLoadSkipsBadLines checks that malformed entries are dropped and a
+// malformed size reads as unmeasured
+func TestLoadSkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	content := "# try index v2 depth=1\n" +
+		"d\t.\t5\n" +
+		"t\ta\t1\t10\t2\n" +
+		"t\tb\t1\tx\t2\n" +
+		"t\tshort\t1\n" +
+		"x\tc\t1\t10\t2\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := Load(path, 1)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(ix.Tries) != 2 || ix.Tries[0].Path != "a" || ix.Tries[1].Path != "b" {
+		t.Fatalf("Tries = %+v, want a and b", ix.Tries)
+	}
+	if ix.Tries[0].Size != 10 || ix.Tries[1].Size != -1 {
+		t.Errorf("sizes = %d, %d, want 10, -1", ix.Tries[0].Size, ix.Tries[1].Size)
+	}
+	if mtime, ok := ix.Dirs[""]; !ok || !mtime.Equal(time.Unix(0, 5)) {
+		t.Errorf("Dirs = %v, want the root at 5ns", ix.Dirs)
+	}
+}
+
+// TestRoundTrip checks that a scanned index loads back equal and fresh,
+// and goes stale when a try is added
+func TestRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	// .try exists once an index was saved, so saving does not touch the root
+	for _, dir := range []string{"2025-01-02-a", "2025/01/2025-01-03-b", ".try"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := Scan(root, 3)
+	if len(ix.Tries) != 2 {
+		t.Fatalf("Scan found %+v, want 2 tries", ix.Tries)
+	}
+	ix.SetSizes(map[string]int64{"2025-01-02-a": 4096})
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(File(root), 3)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.Equal(ix) {
+		t.Errorf("loaded %+v, saved %+v", loaded, ix)
+	}
+	if got := loaded.ByPath()["2025-01-02-a"].Size; got != 4096 {
+		t.Errorf("size = %d, want 4096", got)
+	}
+	if _, err := Load(File(root), 1); err == nil {
+		t.Error("index for depth 3 loaded for depth 1")
+	}
+	if !loaded.Fresh(root) {
+		t.Error("index is stale right after scanning")
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Mkdir(filepath.Join(root, "2025", "01", "2025-01-04-c"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(root, "2025", "01"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Fresh(root) {
+		t.Error("index is fresh after a try was added")
+	}
+}
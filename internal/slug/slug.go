@@ -0,0 +1,141 @@
+// Package slug sanitizes names of tries so they are safe for shells and
+// portable filesystems
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Options controls how names are sanitized
+type Options struct {
+	Lowercase          bool `json:"lowercase"`
+	ASCIIFold          bool `json:"ascii_fold"`
+	CollapseSeparators bool `json:"collapse_separators"`
+	MaxLength          int  `json:"max_length"` // in runes, 0 = unlimited
+}
+
+// DefaultOptions returns the options used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		ASCIIFold:          true,
+		CollapseSeparators: true,
+		MaxLength:          80,
+	}
+}
+
+// Windows refuses these as file names regardless of extension
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// Make turns name into a directory name. Characters other than letters,
+// digits, '-', '_' and '.' become '-', and separators and dots are
+// trimmed from both ends. Letters without an ASCII equivalent are kept
+// even when folding. The result may be empty.
+func Make(name string, opts Options) string {
+	if opts.ASCIIFold {
+		name = Fold(name)
+	}
+	if opts.Lowercase {
+		name = strings.ToLower(name)
+	}
+
+	var out strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 0x80 && (isASCIIAlnum(r) || r == '_' || r == '.' || r == '-'):
+			out.WriteRune(r)
+		case r >= 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)):
+			out.WriteRune(r)
+		default:
+			out.WriteRune('-')
+		}
+	}
+	result := out.String()
+
+	if opts.CollapseSeparators {
+		result = collapse(result)
+	}
+	result = trim(result)
+
+	if opts.MaxLength > 0 {
+		runes := []rune(result)
+		if len(runes) > opts.MaxLength {
+			result = trim(string(runes[:opts.MaxLength]))
+		}
+	}
+
+	stem := strings.ToLower(result)
+	if i := strings.IndexByte(stem, '.'); i >= 0 {
+		stem = stem[:i]
+	}
+	if reservedNames[stem] {
+		result += "_"
+	}
+	return result
+}
+
+// Fold replaces accented letters and common confusables (fullwidth forms,
+// Cyrillic and Greek look-alikes, typographic dashes) with their ASCII
+// counterparts. Other characters are returned unchanged.
+func Fold(text string) string {
+	var out strings.Builder
+	out.Grow(len(text))
+	for _, r := range text {
+		if r < 0x80 {
+			out.WriteRune(r)
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining marks from decomposed input
+		}
+		if folded, ok := foldStrings[r]; ok {
+			out.WriteString(folded)
+			continue
+		}
+		out.WriteRune(FoldRune(r))
+	}
+	return out.String()
+}
+
+// collapse shortens each run of separators to one character, preferring
+// '-' when the run contains one
+func collapse(text string) string {
+	var out strings.Builder
+	run := []rune{}
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if strings.ContainsRune(string(run), '-') {
+			out.WriteRune('-')
+		} else {
+			out.WriteRune(run[0])
+		}
+		run = run[:0]
+	}
+	for _, r := range text {
+		if r == '-' || r == '_' || r == '.' {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		out.WriteRune(r)
+	}
+	flush()
+	return out.String()
+}
+
+// trim drops separators and dots from both ends
+func trim(text string) string {
+	return strings.Trim(text, "-_.")
+}
+
+func isASCIIAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,59 @@
+package slug
+
+import "testing"
+
+// TestMake checks sanitizing, trimming, folding and truncating of names
+func TestMake(t *testing.T) {
+	defaults := DefaultOptions()
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"my-try", defaults, "my-try"},
+		{"my:weird*name", defaults, "my-weird-name"},
+		{"my  spaced   name", defaults, "my-spaced-name"},
+		{"a_-_b", defaults, "a-b"},
+		{"--name...", defaults, "name"},
+		{"_name_", defaults, "name"},
+		{"café crème", defaults, "cafe-creme"},
+		{"café", Options{}, "café"},
+		{"日本語", defaults, "日本語"},
+		{"***", defaults, ""},
+		{"con", defaults, "con_"},
+		{"Aux.txt", defaults, "Aux.txt_"},
+		{"console", defaults, "console"},
+		{"MyTry", Options{Lowercase: true}, "mytry"},
+		{"a--b", Options{}, "a--b"},
+		{"abcdef", Options{MaxLength: 3}, "abc"},
+		{"ab-cdef", Options{MaxLength: 3}, "ab"},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name, tt.opts); got != tt.want {
+			t.Errorf("Make(%q, %+v) = %q, want %q", tt.name, tt.opts, got, tt.want)
+		}
+	}
+}
+
+// TestFold checks that accents, ligatures and look-alikes become ASCII
+// while other characters are kept
+func TestFold(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"café", "cafe"},
+		{"café", "cafe"},
+		{"straße", "strasse"},
+		{"Œuvre", "OEuvre"},
+		{"ас", "ac"},
+		{"a–b", "a-b"},
+		{"日本", "日本"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.text); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package tags stores the labels attached to tries
+package tags
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/amulcse/try/internal/slug"
+)
+
+// Store maps tries, by their path relative to the tries root, to their tags
+type Store struct {
+	path string
+	tags map[string][]string
+}
+
+// File returns where the tags of the tries under root are kept
+func File(root string) string {
+	return filepath.Join(root, ".try", "tags")
+}
+
+// Load reads the store at path; a missing file is an empty store
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, tags: map[string][]string{}}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, list, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || name == "" {
+			continue
+		}
+		for _, tag := range strings.Split(list, ",") {
+			if tag != "" {
+				s.tags[name] = append(s.tags[name], tag)
+			}
+		}
+	}
+	return s, scanner.Err()
+}
+
+// Normalize turns user input into a tag: lowercase, ASCII where possible,
+// and free of separators. It returns "" when nothing usable is left.
+func Normalize(tag string) string {
+	return slug.Make(tag, slug.Options{
+		Lowercase:          true,
+		ASCIIFold:          true,
+		CollapseSeparators: true,
+		MaxLength:          32,
+	})
+}
+
+// Parse splits input such as "work, infra -old" into tags to add and tags
+// to remove (those prefixed with '-')
+func Parse(input string) (add, remove []string) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		if rest, ok := strings.CutPrefix(field, "-"); ok {
+			if tag := Normalize(rest); tag != "" {
+				remove = append(remove, tag)
+			}
+			continue
+		}
+		if tag := Normalize(field); tag != "" {
+			add = append(add, tag)
+		}
+	}
+	return add, remove
+}
+
+// Tags returns the sorted tags of the try at name
+func (s *Store) Tags(name string) []string {
+	return s.tags[name]
+}
+
+// Update adds and removes tags on the named tries and saves the store
+func (s *Store) Update(names, add, remove []string) error {
+	for _, name := range names {
+		set := map[string]bool{}
+		for _, tag := range s.tags[name] {
+			set[tag] = true
+		}
+		for _, tag := range add {
+			set[tag] = true
+		}
+		for _, tag := range remove {
+			delete(set, tag)
+		}
+		if len(set) == 0 {
+			delete(s.tags, name)
+			continue
+		}
+		list := make([]string, 0, len(set))
+		for tag := range set {
+			list = append(list, tag)
+		}
+		sort.Strings(list)
+		s.tags[name] = list
+	}
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(s.tags))
+	for name := range s.tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s\t%s\n", name, strings.Join(s.tags[name], ","))
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(out.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestParse checks splitting input into tags to add and remove
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		add    []string
+		remove []string
+	}{
+		{"", nil, nil},
+		{"work", []string{"work"}, nil},
+		{"work, infra -old", []string{"work", "infra"}, []string{"old"}},
+		{"Work\tCafé  My Tag", []string{"work", "cafe", "my", "tag"}, nil},
+		{"-a,-b", nil, []string{"a", "b"}},
+		{"- *** -", nil, nil},
+	}
+	for _, tt := range tests {
+		add, remove := Parse(tt.input)
+		if !slices.Equal(add, tt.add) || !slices.Equal(remove, tt.remove) {
+			t.Errorf("Parse(%q) = %q, %q, want %q, %q", tt.input, add, remove, tt.add, tt.remove)
+		}
+	}
+}
+
+// TestRoundTrip checks that updated tags are saved and loaded back sorted
+func TestRoundTrip(t *testing.T) {
+	path := File(t.TempDir())
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of missing file: %v", err)
+	}
+	if got := s.Tags("a"); got != nil {
+		t.Fatalf("empty store has tags %q", got)
+	}
+
+	add, _ := Parse("zeta, alpha")
+	if err := s.Update([]string{"a", "2025/01/b"}, add, nil); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	_, remove := Parse("-zeta")
+	if err := s.Update([]string{"2025/01/b"}, []string{"beta"}, remove); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string][]string{
+		"a":         {"alpha", "zeta"},
+		"2025/01/b": {"alpha", "beta"},
+		"c":         nil,
+	}
+	for name, tags := range want {
+		if got := loaded.Tags(name); !slices.Equal(got, tags) {
+			t.Errorf("Tags(%q) = %q, want %q", name, got, tags)
+		}
+	}
+
+	if err := loaded.Update([]string{"a", "2025/01/b"}, nil, []string{"alpha", "beta", "zeta"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("store without tags saved %q", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind in %s", filepath.Dir(path))
+	}
+}
@@ -0,0 +1,97 @@
+// Package trust records which per-try files the user approved for sourcing
+package trust
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store maps file paths to the hash of their approved content. A file is
+// trusted only while its path and content both match an approval.
+type Store struct {
+	path    string
+	allowed map[string]string // file path -> hash
+}
+
+// Load reads the store at path; a missing file is an empty store
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, allowed: map[string]string{}}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, file, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || hash == "" || file == "" {
+			continue
+		}
+		s.allowed[file] = hash
+	}
+	return s, scanner.Err()
+}
+
+// Hash returns the hash an approval of file with content data is recorded
+// under
+func Hash(file string, data []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(file))
+	sum.Write([]byte{0})
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// Trusted reports whether data, read from file, is the approved content of
+// file. Callers check the content they read, so a file changed after the
+// check is never used.
+func (s *Store) Trusted(file string, data []byte) bool {
+	want, ok := s.allowed[file]
+	return ok && Hash(file, data) == want
+}
+
+// Allow approves data as the content of file and saves the store
+func (s *Store) Allow(file string, data []byte) error {
+	s.allowed[file] = Hash(file, data)
+	return s.save()
+}
+
+// Deny forgets any approval of file and saves the store
+func (s *Store) Deny(file string) error {
+	if _, ok := s.allowed[file]; !ok {
+		return nil
+	}
+	delete(s.allowed, file)
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	files := make([]string, 0, len(s.allowed))
+	for file := range s.allowed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var out strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&out, "%s %s\n", s.allowed[file], file)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(out.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
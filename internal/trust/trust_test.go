@@ -0,0 +1,86 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestHash checks that the hash is stable across releases and covers both
+// the path and the content
+func TestHash(t *testing.T) {
+	// sha256 of "/t/.tryrc\x00export A=1\n"; changing it forgets every
+	// approval users have made
+	const want = "933efadd5254272278c22fed1115817971a1de1148677a9c97509b90347728b3"
+	if got := Hash("/t/.tryrc", []byte("export A=1\n")); got != want {
+		t.Errorf("Hash = %s, want %s", got, want)
+	}
+
+	base := Hash("/t/.tryrc", []byte("a"))
+	for _, other := range []string{
+		Hash("/u/.tryrc", []byte("a")),
+		Hash("/t/.tryrc", []byte("b")),
+		Hash("/t/.tryrc", nil),
+		Hash("/t/.tryrca", nil),
+	} {
+		if other == base {
+			t.Errorf("different input hashed to %s", base)
+		}
+	}
+}
+
+// TestStore checks that approvals are saved, tied to the content they
+// approved and can be withdrawn
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trusted")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of missing file: %v", err)
+	}
+	if s.Trusted("/t/.tryrc", []byte("a")) {
+		t.Fatal("empty store trusts a file")
+	}
+	if err := s.Allow("/t/.tryrc", []byte("a")); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if err := s.Allow("/u/.tryrc", []byte("b")); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	tests := []struct {
+		file string
+		data string
+		want bool
+	}{
+		{"/t/.tryrc", "a", true},
+		{"/t/.tryrc", "a\n", false},
+		{"/u/.tryrc", "b", true},
+		{"/u/.tryrc", "a", false},
+		{"/v/.tryrc", "a", false},
+	}
+	for _, tt := range tests {
+		if got := loaded.Trusted(tt.file, []byte(tt.data)); got != tt.want {
+			t.Errorf("Trusted(%q, %q) = %v, want %v", tt.file, tt.data, got, tt.want)
+		}
+	}
+
+	if err := loaded.Deny("/t/.tryrc"); err != nil {
+		t.Fatalf("Deny: %v", err)
+	}
+	if err := loaded.Deny("/v/.tryrc"); err != nil {
+		t.Fatalf("Deny of unknown file: %v", err)
+	}
+	loaded, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Trusted("/t/.tryrc", []byte("a")) {
+		t.Error("denied file is still trusted")
+	}
+	if !loaded.Trusted("/u/.tryrc", []byte("b")) {
+		t.Error("Deny forgot another file")
+	}
+}
@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fields of the entry line format
+const (
+	fieldIcon  = "icon"
+	fieldName  = "name"
+	fieldDate  = "date"
+	fieldAge   = "age"
+	fieldScore = "score"
+	fieldSize  = "size"
+	fieldTags  = "tags"
+	fieldGit   = "git"
+	fieldRoot  = "root"
+)
+
+var formatFields = []string{fieldIcon, fieldName, fieldDate, fieldAge, fieldScore, fieldSize, fieldTags, fieldGit, fieldRoot}
+
+type segmentKind int
+
+const (
+	segmentText segmentKind = iota
+	segmentField
+	segmentGroup
+)
+
+// segment is a piece of a format: text (style tokens already expanded), a
+// field, or a group of segments dropped when all its fields are empty
+type segment struct {
+	kind  segmentKind
+	text  string // text, or the field name
+	group []segment
+}
+
+// lineFormat is a parsed entry line format
+type lineFormat struct {
+	left, right []segment
+}
+
+// parseFormat parses an entry line format. Text outside fields is expanded
+// like any token text; brackets that do not pair up are kept as text.
+func parseFormat(format string) *lineFormat {
+	f := &lineFormat{}
+	left, right, ok := strings.Cut(format, "{right}")
+	f.left = parseSegments(left)
+	if ok {
+		f.right = parseSegments(right)
+	}
+	return f
+}
+
+func parseSegments(text string) []segment {
+	var segments []segment
+	for text != "" {
+		if text[0] == '[' {
+			if end := strings.IndexByte(text, ']'); end > 0 {
+				segments = append(segments, segment{kind: segmentGroup, group: parseFields(text[1:end])})
+				text = text[end+1:]
+				continue
+			}
+		}
+		next := strings.IndexByte(text[1:], '[')
+		if next < 0 {
+			next = len(text)
+		} else {
+			next++
+		}
+		segments = append(segments, parseFields(text[:next])...)
+		text = text[next:]
+	}
+	return segments
+}
+
+func parseFields(text string) []segment {
+	var segments []segment
+	literal := ""
+	for text != "" {
+		field := ""
+		if text[0] == '{' {
+			if end := strings.IndexByte(text, '}'); end > 0 && isFormatField(text[1:end]) {
+				field = text[1:end]
+			}
+		}
+		if field == "" {
+			literal += text[:1]
+			text = text[1:]
+			continue
+		}
+		if literal != "" {
+			segments = append(segments, segment{kind: segmentText, text: Expand(literal)})
+			literal = ""
+		}
+		segments = append(segments, segment{kind: segmentField, text: field})
+		text = text[len(field)+2:]
+	}
+	if literal != "" {
+		segments = append(segments, segment{kind: segmentText, text: Expand(literal)})
+	}
+	return segments
+}
+
+func isFormatField(name string) bool {
+	for _, field := range formatFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// renderSegments fills in segments with the values of their fields
+func renderSegments(segments []segment, value func(string) string) string {
+	var out strings.Builder
+	for _, seg := range segments {
+		switch seg.kind {
+		case segmentText:
+			out.WriteString(seg.text)
+		case segmentField:
+			out.WriteString(value(seg.text))
+		case segmentGroup:
+			var group strings.Builder
+			filled := false
+			for _, g := range seg.group {
+				if g.kind == segmentField {
+					v := value(g.text)
+					filled = filled || v != ""
+					group.WriteString(v)
+				} else {
+					group.WriteString(g.text)
+				}
+			}
+			if filled {
+				out.WriteString(group.String())
+			}
+		}
+	}
+	return out.String()
+}
+
+// fieldValue returns a field of the entry line. The name is left to the
+// caller, which truncates it to fit.
+func (s *Selector) fieldValue(entry Entry, field string) string {
+	item := entry.Item
+	switch field {
+	case fieldIcon:
+		if indexOf(s.marked, item.Path) >= 0 {
+			if s.deleteMode {
+				return s.icon("🗑️", "x")
+			}
+			return s.icon("📌", "*")
+		}
+		return s.icon("📁", "/")
+	case fieldDate:
+		return item.Ctime.Format("2006-01-02")
+	case fieldAge:
+		return FormatRelativeTime(item.Mtime)
+	case fieldScore:
+		if s.contentSearch {
+			if entry.Score == 1 {
+				return "1 hit"
+			}
+			return fmt.Sprintf("%d hits", int(entry.Score))
+		}
+		return fmt.Sprintf("%.1f", entry.Score)
+	case fieldSize:
+		if size, ok := s.sizes[item.Path]; ok {
+			return FormatSize(size)
+		}
+		if s.sizing != nil {
+			return "measuring…"
+		}
+	case fieldTags:
+		if len(item.Tags) > 0 {
+			return "#" + strings.Join(item.Tags, " #")
+		}
+	case fieldGit:
+		return s.gitBranch(item.Path)
+	case fieldRoot:
+		return abbreviateHome(s.basePath)
+	}
+	return ""
+}
+
+// icon returns emoji, or plain when emoji are turned off
+func (s *Selector) icon(emoji, plain string) string {
+	if s.config.Display.Emoji {
+		return emoji
+	}
+	return plain
+}
+
+// gitBranch returns the branch checked out in dir, or the start of the
+// commit when detached, read from .git/HEAD and remembered until the list
+// is reloaded. It is empty for directories that are not repositories.
+func (s *Selector) gitBranch(dir string) string {
+	if branch, ok := s.branches[dir]; ok {
+		return branch
+	}
+	if s.branches == nil {
+		s.branches = map[string]string{}
+	}
+	branch := readGitHead(filepath.Join(dir, ".git"))
+	s.branches[dir] = branch
+	return branch
+}
+
+func readGitHead(gitDir string) string {
+	gitDir, ok := resolveGitDir(gitDir)
+	if !ok {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return ref
+	}
+	if len(head) >= 7 {
+		return head[:7]
+	}
+	return ""
+}
+
+// resolveGitDir returns the git directory a .git entry stands for: the
+// entry itself, or for a worktree the directory its .git file points at
+func resolveGitDir(gitDir string) (string, bool) {
+	data, err := os.ReadFile(gitDir)
+	if err != nil {
+		return gitDir, true
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(gitDir), target)
+	}
+	return target, true
+}
+
+// abbreviateHome writes paths below the home directory with ~
+func abbreviateHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return path
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetContentSearch starts the selector searching inside tries (file names,
+// notes and, with grep.contents, every text file) instead of their names
+func (s *Selector) SetContentSearch(on bool) {
+	s.contentSearch = on
+	s.resetMatches()
+}
+
+// renderSnippetLine previews the first hit of the selected try
+func (s *Selector) renderSnippetLine(tries []Entry) string {
+	if strings.TrimSpace(s.inputBuffer) == "" {
+		hint := "  Type to search inside tries"
+		if label := s.keymap.label(s.mode, actToggleGrep, false); label != "" {
+			hint += " (" + label + ": search names)"
+		}
+		return dim(hint)
+	}
+	if s.cursorPos >= len(tries) {
+		return ""
+	}
+	hit, ok := s.snippets[tries[s.cursorPos].Item.Path]
+	if !ok {
+		return ""
+	}
+
+	// Snippets come from files in the tries, so control characters must
+	// not reach the terminal
+	file, _, _ := sanitizeSnippet(hit.File, 0, 0)
+	start, end := hit.Start, hit.End
+	if start >= end || end > len(hit.Text) {
+		start, end = 0, 0
+	}
+	text, start, end := sanitizeSnippet(hit.Text, start, end)
+
+	var out strings.Builder
+	out.WriteString("  ")
+	if hit.Line > 0 {
+		out.WriteString(dim(fmt.Sprintf("%s:%d: ", file, hit.Line)))
+	}
+	if start < end {
+		out.WriteString(text[:start])
+		out.WriteString(highlight(text[start:end]))
+		out.WriteString(text[end:])
+	} else {
+		out.WriteString(text)
+	}
+	return s.truncateLine(out.String())
+}
+
+// sanitizeSnippet makes text safe to print: tabs become spaces, other C0
+// controls, DEL and C1 controls are dropped, and invalid UTF-8 becomes
+// U+FFFD. The byte range start:end is shifted to match.
+func sanitizeSnippet(text string, start, end int) (string, int, int) {
+	var b strings.Builder
+	newStart, newEnd := 0, 0
+	for i := 0; i < len(text); {
+		if i <= start {
+			newStart = b.Len()
+		}
+		if i <= end {
+			newEnd = b.Len()
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(utf8.RuneError)
+		default:
+			b.WriteString(text[i : i+size])
+		}
+		i += size
+	}
+	if start >= len(text) {
+		newStart = b.Len()
+	}
+	if end >= len(text) {
+		newEnd = b.Len()
+	}
+	return b.String(), newStart, newEnd
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// querySyntax summarizes the query syntax of spec/fuzzy_matching.md
+var querySyntax = [][2]string{
+	{"abc", "Fuzzy: the characters in order"},
+	{"'abc", "Exact substring"},
+	{"^abc", "Name starts with abc"},
+	{"abc$", "Name ends with abc"},
+	{"!abc", "Entries without abc"},
+	{"a b", "Both terms"},
+	{"a | b", "Either term"},
+}
+
+// showHelp draws the key bindings of the current mode and the query syntax
+// over the list until any key is pressed
+func (s *Selector) showHelp() {
+	for {
+		s.renderHelp()
+		if key := s.readKey(); key != "" {
+			break
+		}
+	}
+	s.NeedsRedraw = true
+}
+
+// helpLines returns the lines of the help overlay: the bindings of the
+// current mode, then the query syntax
+func (s *Selector) helpLines() []string {
+	type row struct{ keys, desc string }
+	var rows []row
+	b := s.keymap.modes[s.mode]
+	for _, a := range keyActions {
+		var labels []string
+		for _, seq := range b.keys(a.name) {
+			labels = append(labels, keyLabel(seq, true))
+		}
+		if len(labels) > 0 {
+			rows = append(rows, row{strings.Join(labels, " "), a.desc})
+		}
+	}
+	width := 0
+	for _, r := range rows {
+		width = max(width, visibleLen(r.keys))
+	}
+	for _, q := range querySyntax {
+		width = max(width, len(q[0]))
+	}
+
+	title := "Keys"
+	if s.keymap.modes[modeNormal] != nil {
+		title = strings.ToUpper(s.mode[:1]) + s.mode[1:] + " mode keys"
+	}
+	lines := []string{styled("h2", title)}
+	for _, r := range rows {
+		lines = append(lines, fmt.Sprintf("  %s%s  %s", highlight(r.keys), strings.Repeat(" ", width-visibleLen(r.keys)), r.desc))
+	}
+	lines = append(lines, "", styled("h2", "Query syntax"))
+	for _, q := range querySyntax {
+		lines = append(lines, fmt.Sprintf("  %s%s  %s", highlight(q[0]), strings.Repeat(" ", width-len(q[0])), q[1]))
+	}
+	return lines
+}
+
+// renderHelp draws the help overlay, flowing its lines into as many
+// columns as the screen needs
+func (s *Selector) renderHelp() {
+	s.refreshSize()
+	var out strings.Builder
+	out.WriteString(s.home())
+
+	header := s.centerText(s.icon("❓", "") + accent("  Help"))
+	out.WriteString("\r" + ansiClearEOL + header + "\n")
+	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
+
+	lines := s.helpLines()
+	rows := max(s.height-4, 1) // header and footer
+	columns := (len(lines) + rows - 1) / rows
+	colWidth := (s.width - 1) / columns
+	for r := 0; r < rows; r++ {
+		var line strings.Builder
+		for c := 0; c < columns; c++ {
+			i := c*rows + r
+			if i >= len(lines) {
+				break
+			}
+			cell := lines[i]
+			if visibleLen(cell) > colWidth-1 {
+				cell = truncateWithAnsi(cell, colWidth-2) + "…"
+			}
+			line.WriteString(cell)
+			if c < columns-1 {
+				line.WriteString(strings.Repeat(" ", max(colWidth-visibleLen(cell), 0)))
+			}
+		}
+		out.WriteString("\r" + ansiClearEOL + strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	// Footer
+	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
+	out.WriteString("\r" + ansiClearEOL + s.centerText(dim("Press any key to close")))
+	out.WriteString(ansiReset)
+
+	s.io.WriteString(out.String())
+}
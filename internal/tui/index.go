@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/amulcse/try/internal/index"
+	"github.com/amulcse/try/internal/tags"
+)
+
+// loadAllTries fills the list the first time it is needed and after a
+// mutation cleared it. The first load draws from the on-disk index when no
+// directory changed since it was written, and a live scan reconciles it in
+// the background; later loads scan the root again.
+func (s *Selector) loadAllTries() {
+	if s.allTries != nil {
+		return
+	}
+
+	s.reconciling = nil
+	cached, err := index.Load(index.File(s.basePath), s.config.Depth)
+	if s.index == nil && err == nil && cached.Fresh(s.basePath) {
+		s.useIndex(cached)
+		s.startReconcile(cached)
+		return
+	}
+
+	// Saving must not create .try after the scan, changing the root's mtime
+	persist := s.prepareIndex()
+	ix := index.Scan(s.basePath, s.config.Depth)
+	if cached != nil {
+		ix.KeepSizes(cached.ByPath())
+	}
+	if persist && (cached == nil || !ix.Equal(cached)) {
+		s.saveIndex(ix)
+	}
+	s.useIndex(ix)
+}
+
+// saveTestIndex lets runs driven by --and-exit or --and-keys save the index
+var saveTestIndex bool
+
+// SaveIndexInTests makes test runs save the index, which they otherwise
+// leave alone, so tests of the index can check what is written
+func SaveIndexInTests() {
+	saveTestIndex = true
+}
+
+// prepareIndex creates <root>/.try and reports whether the index may be
+// saved there. Test runs do not save it, and neither do roots that are
+// missing or not writable.
+func (s *Selector) prepareIndex() bool {
+	if (s.testRenderOnce || s.testHadKeys) && !saveTestIndex {
+		return false
+	}
+	if _, err := os.Stat(s.basePath); err != nil {
+		return false
+	}
+	err := os.MkdirAll(filepath.Dir(index.File(s.basePath)), 0755)
+	if err != nil && !errors.Is(err, fs.ErrPermission) && !errors.Is(err, syscall.EROFS) {
+		s.setStatus("Could not save the index: " + err.Error())
+	}
+	return err == nil
+}
+
+// saveIndex saves ix, reporting a failure in the status line
+func (s *Selector) saveIndex(ix *index.Index) {
+	if err := ix.Save(); err != nil {
+		s.setStatus("Could not save the index: " + err.Error())
+	}
+}
+
+// useIndex builds the list from ix
+func (s *Selector) useIndex(ix *index.Index) {
+	s.index = ix
+	s.stopSizing()
+	s.sizes = nil
+	s.branches = nil
+	now := time.Now()
+
+	s.allTries = make([]Item, 0, len(ix.Tries))
+	for _, t := range ix.Tries {
+		name := path.Base(t.Path)
+		hoursSinceAccess := now.Sub(t.Mtime).Hours()
+		baseScore := 3.0 / math.Sqrt(hoursSinceAccess+1)
+
+		// Date prefix bonus
+		if datePrefixRe.MatchString(name) {
+			baseScore += 2.0
+		}
+
+		s.allTries = append(s.allTries, Item{
+			Text:      t.Path,
+			Basename:  name,
+			Path:      filepath.Join(s.basePath, filepath.FromSlash(t.Path)),
+			Ctime:     createdTime(name, t.Mtime),
+			Mtime:     t.Mtime,
+			BaseScore: baseScore,
+		})
+	}
+
+	if store, err := tags.Load(tags.File(s.basePath)); err == nil {
+		for i := range s.allTries {
+			s.allTries[i].Tags = store.Tags(s.allTries[i].Text)
+		}
+	}
+	s.watchTries()
+}
+
+// startReconcile scans the root in the background. The scan yields nil
+// when it matches cached.
+func (s *Selector) startReconcile(cached *index.Index) {
+	root, depth := s.basePath, s.config.Depth
+	frozen := cached.Clone()
+	done := make(chan *index.Index, 1)
+	wake := s.wake
+	go func() {
+		ix := index.Scan(root, depth)
+		ix.KeepSizes(frozen.ByPath())
+		if ix.Equal(frozen) {
+			ix = nil
+		}
+		done <- ix
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}()
+	s.reconciling = done
+
+	// Tests need the list the keys they send act on
+	if s.testHadKeys || s.testRenderOnce {
+		s.applyReconcile(<-done)
+	}
+}
+
+// pollReconcile applies the background scan once it finished
+func (s *Selector) pollReconcile() {
+	if s.reconciling == nil {
+		return
+	}
+	select {
+	case ix := <-s.reconciling:
+		s.applyReconcile(ix)
+	default:
+	}
+}
+
+// applyReconcile swaps in the scanned index, if it differs from the list,
+// and saves it
+func (s *Selector) applyReconcile(ix *index.Index) {
+	s.reconciling = nil
+	if ix == nil {
+		return
+	}
+	ix.KeepSizes(s.index.ByPath())
+	if s.prepareIndex() {
+		s.saveIndex(ix)
+	}
+	if s.matcher != nil {
+		if tries := s.getTries(); s.cursorPos < len(tries) {
+			s.follow = tries[s.cursorPos].Item.Path
+		}
+	}
+	s.useIndex(ix)
+	s.resetMatches()
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amulcse/try/internal/config"
+)
+
+// Escape sequences of inline mode, which draws in a region below the
+// prompt and finds its top again by saving the cursor there
+const (
+	ansiSaveCursor    = "\x1b7"
+	ansiRestoreCursor = "\x1b8"
+	ansiClearBelow    = "\x1b[J"
+	ansiReportCursor  = "\x1b[6n"
+)
+
+// minInlineRows keeps room for the header, the footer and a few entries
+const minInlineRows = 10
+
+// locateWait is how long the terminal has to report the cursor position
+const locateWait = 200 * time.Millisecond
+
+var cursorReportRe = regexp.MustCompile(`\x1b\[(\d+);(\d+)R`)
+
+// regionRows returns the rows of the inline region on a terminal of rows
+// rows
+func (s *Selector) regionRows(rows int) int {
+	n, _ := config.ParseHeight(s.config.Height, rows)
+	return min(max(n, minInlineRows), rows)
+}
+
+// reserveRegion makes room for the region below the cursor, scrolling the
+// terminal if needed, and saves the cursor at its top
+func (s *Selector) reserveRegion() {
+	var out strings.Builder
+	if s.height > 1 {
+		out.WriteString(strings.Repeat("\n", s.height-1))
+		fmt.Fprintf(&out, "\x1b[%dA", s.height-1)
+	}
+	out.WriteString("\r" + ansiSaveCursor)
+	s.io.WriteString(out.String())
+}
+
+// locateRegion asks the terminal for the row the region starts on, so
+// mouse reports can be mapped to it. Keys that arrive meanwhile are kept.
+func (s *Selector) locateRegion() {
+	s.io.WriteString(ansiRestoreCursor + ansiReportCursor)
+	fd := int(os.Stdin.Fd())
+	var got []byte
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(locateWait)
+	for time.Now().Before(deadline) && inputReady(fd, time.Until(deadline)) {
+		n, err := os.Stdin.Read(buf)
+		got = append(got, buf[:n]...)
+		if m := cursorReportRe.FindSubmatchIndex(got); m != nil {
+			s.regionTop, _ = strconv.Atoi(string(got[m[2]:m[3]]))
+			got = append(got[:m[0]], got[m[1]:]...)
+			break
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.keys.feed(got)
+}
+
+// home returns the sequence that moves to the top left of the frame
+func (s *Selector) home() string {
+	if s.inline {
+		return ansiRestoreCursor
+	}
+	return ansiHome
+}
+
+// cursorTo returns the sequence that moves to a 1-based row and column of
+// the frame
+func (s *Selector) cursorTo(row, col int) string {
+	if !s.inline {
+		return fmt.Sprintf("\x1b[%d;%dH", row, col)
+	}
+	move := ansiRestoreCursor
+	if row > 1 {
+		move += fmt.Sprintf("\x1b[%dB", row-1)
+	}
+	return move + fmt.Sprintf("\x1b[%dG", col)
+}
+
+// clearFrame returns the sequence that blanks the frame and moves to its
+// top left
+func (s *Selector) clearFrame() string {
+	if s.inline {
+		return ansiRestoreCursor + ansiClearBelow
+	}
+	return ansiClearScreen + ansiHome
+}
@@ -0,0 +1,277 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// escWait is how long a lone ESC waits for the rest of a sequence before
+// it counts as the Escape key
+const escWait = 25 * time.Millisecond
+
+// inputPoll is how often the input reader checks whether it was stopped
+// when it has no pipe to be woken through
+const inputPoll = 50 * time.Millisecond
+
+// Bracketed paste: the terminal wraps pasted text in these sequences once
+// ansiPasteOn asked it to. A decoded paste is KeyPaste followed by the text.
+const (
+	ansiPasteOn  = "\x1b[?2004h"
+	ansiPasteOff = "\x1b[?2004l"
+	KeyPaste     = "\x1b[200~"
+	pasteEnd     = "\x1b[201~"
+)
+
+// Keys with several encodings are decoded to one of them
+const (
+	keyHome = "\x1b[H"
+	keyEnd  = "\x1b[F"
+)
+
+// keyDecoder splits terminal input into keys: control bytes, UTF-8
+// characters, CSI and SS3 sequences with their modifiers, Alt plus a key
+// (ESC before it) and bracketed pastes
+type keyDecoder struct {
+	buf []byte
+}
+
+func (d *keyDecoder) feed(data []byte) {
+	d.buf = append(d.buf, data...)
+}
+
+// next returns the first complete key. With flush, an incomplete sequence
+// is returned as the keys it starts with instead of waiting for more input;
+// a paste always waits for its end.
+func (d *keyDecoder) next(flush bool) (string, bool) {
+	if len(d.buf) == 0 {
+		return "", false
+	}
+	key, n := decodeKey(d.buf, flush)
+	if n == 0 {
+		return "", false
+	}
+	d.buf = d.buf[n:]
+	return key, true
+}
+
+// incomplete reports whether the buffer holds the start of a key that
+// waits for escWait, as opposed to nothing or a paste in progress
+func (d *keyDecoder) incomplete() bool {
+	return len(d.buf) > 0 && !bytes.HasPrefix(d.buf, []byte(KeyPaste))
+}
+
+// SplitKeys decodes a string of terminal input into keys
+func SplitKeys(input string) []string {
+	d := keyDecoder{buf: []byte(input)}
+	keys := []string{}
+	for {
+		key, ok := d.next(true)
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+	}
+	if len(d.buf) > 0 {
+		keys = append(keys, string(d.buf)) // an unterminated paste
+	}
+	return keys
+}
+
+// decodeKey decodes the key at the start of b and returns it with the
+// number of bytes it took, which is 0 when more input is needed
+func decodeKey(b []byte, flush bool) (string, int) {
+	switch {
+	case b[0] == 0x1b:
+		return decodeEscape(b, flush)
+	case b[0] < utf8.RuneSelf:
+		return string(b[:1]), 1
+	case !utf8.FullRune(b):
+		if flush {
+			return string(b[:1]), 1
+		}
+		return "", 0
+	}
+	_, size := utf8.DecodeRune(b)
+	return string(b[:size]), size
+}
+
+func decodeEscape(b []byte, flush bool) (string, int) {
+	if len(b) == 1 {
+		if flush {
+			return "\x1b", 1
+		}
+		return "", 0
+	}
+	switch b[1] {
+	case '[':
+		return decodeCSI(b, flush)
+	case 'O':
+		if len(b) == 2 {
+			if flush {
+				return "\x1bO", 2 // Alt-Shift-O
+			}
+			return "", 0
+		}
+		return ss3Key(b[2]), 3
+	case 0x1b:
+		return "\x1b", 1 // Escape, then whatever the second ESC starts
+	}
+	key, n := decodeKey(b[1:], flush)
+	if n == 0 {
+		return "", 0
+	}
+	return "\x1b" + key, n + 1
+}
+
+// decodeCSI decodes ESC [ parameters intermediates final
+func decodeCSI(b []byte, flush bool) (string, int) {
+	i := 2
+	for i < len(b) && b[i] >= 0x30 && b[i] <= 0x3f {
+		i++
+	}
+	for i < len(b) && b[i] >= 0x20 && b[i] <= 0x2f {
+		i++
+	}
+	if i == len(b) {
+		if flush {
+			return string(b), len(b)
+		}
+		return "", 0
+	}
+	if b[i] < 0x40 || b[i] > 0x7e {
+		return "\x1b[", 2 // not a sequence: Alt-[
+	}
+	seq := string(b[:i+1])
+
+	if seq == KeyPaste {
+		end := bytes.Index(b[i+1:], []byte(pasteEnd))
+		if end < 0 {
+			return "", 0
+		}
+		text := string(b[i+1 : i+1+end])
+		return KeyPaste + text, i + 1 + end + len(pasteEnd)
+	}
+	switch seq {
+	case "\x1b[1~", "\x1b[7~", "\x1b[1;1H":
+		seq = keyHome
+	case "\x1b[4~", "\x1b[8~", "\x1b[1;1F":
+		seq = keyEnd
+	}
+	return seq, i + 1
+}
+
+// ss3Key decodes ESC O final, which some terminals send for the arrows,
+// Home, End and keypad Enter, to the CSI form
+func ss3Key(final byte) string {
+	switch final {
+	case 'A', 'B', 'C', 'D':
+		return "\x1b[" + string(final)
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case 'M':
+		return "\r"
+	}
+	return "\x1bO" + string(final)
+}
+
+// startInput reads stdin in the background. Reads only start once input is
+// ready, so after stopInput later input is left to whoever reads stdin
+// next.
+func (s *Selector) startInput() {
+	in := make(chan []byte)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	wake, wakeW, err := os.Pipe()
+	if err != nil {
+		wake, wakeW = nil, nil
+	}
+	s.input, s.stopInputCh, s.inputDone, s.inputWake = in, stop, done, wakeW
+	go func() {
+		defer close(done)
+		defer close(in)
+		if wake != nil {
+			defer wake.Close()
+		}
+		fd := int(os.Stdin.Fd())
+		buf := make([]byte, 4096)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if !waitInput(fd, wake) {
+				continue
+			}
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				select {
+				case in <- append([]byte(nil), buf[:n]...):
+				case <-stop:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// stopInput stops the background reader, if any, and waits until it no
+// longer reads stdin, so nothing typed after the selector is swallowed.
+// Where reads cannot be interrupted the reader exits after its next read.
+func (s *Selector) stopInput() {
+	if s.stopInputCh == nil {
+		return
+	}
+	close(s.stopInputCh)
+	if s.inputWake != nil {
+		s.inputWake.Close()
+	}
+	if inputInterruptible {
+		<-s.inputDone
+	}
+	s.stopInputCh, s.inputDone, s.inputWake = nil, nil, nil
+}
+
+// insertText inserts pasted text at cursor. Line breaks and tabs become
+// spaces; other characters that printable rejects are dropped.
+func insertText(buffer string, cursor int, text string, printable func(rune) bool) (string, int) {
+	var b strings.Builder
+	for _, r := range text {
+		if r == '\n' || r == '\r' || r == '\t' {
+			r = ' '
+		}
+		if printable(r) {
+			b.WriteRune(r)
+		}
+	}
+	return buffer[:cursor] + b.String() + buffer[cursor:], cursor + b.Len()
+}
+
+// prevRune returns the offset of the character before cursor in text
+func prevRune(text string, cursor int) int {
+	_, size := utf8.DecodeLastRuneInString(text[:cursor])
+	return cursor - size
+}
+
+// nextRune returns the offset of the character after cursor in text
+func nextRune(text string, cursor int) int {
+	_, size := utf8.DecodeRuneInString(text[cursor:])
+	return cursor + size
+}
+
+// typedRune returns the character a key types, if it is a single one
+func typedRune(key string) (rune, bool) {
+	r, size := utf8.DecodeRuneInString(key)
+	if size == 0 || size != len(key) || r == utf8.RuneError {
+		return 0, false
+	}
+	return r, true
+}
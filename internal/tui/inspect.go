@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tryReport is what the delete dialog shows about a try: its size and the
+// work deleting it would lose
+type tryReport struct {
+	size       int64
+	sized      bool
+	checked    bool   // the git checks finished
+	dirty      bool   // uncommitted changes
+	unpushed   int    // commits that exist nowhere else
+	worktree   bool   // a git worktree of another repository
+	source     string // the repository a worktree belongs to
+	sourceGone bool   // the worktree's repository no longer exists
+}
+
+// reportUpdate carries a size or the git checks of one try
+type reportUpdate struct {
+	index  int
+	sized  bool
+	report tryReport
+}
+
+// inspection gathers reports on the tries in the delete dialog. Each try
+// is measured and checked in goroutines of its own; their results are
+// applied by poll and wait, on the selector's goroutine.
+type inspection struct {
+	reports     []tryReport
+	updates     chan reportUpdate
+	pendingSize int
+	pendingGit  int
+	cancel      context.CancelFunc
+}
+
+// startInspection starts measuring and checking items. Sizes already
+// known from size sorting are reused.
+func (s *Selector) startInspection(items []Entry) *inspection {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := &inspection{
+		reports: make([]tryReport, len(items)),
+		updates: make(chan reportUpdate, 2*len(items)),
+		cancel:  cancel,
+	}
+	wake := s.wake
+	send := func(u reportUpdate) {
+		in.updates <- u
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	for i, item := range items {
+		dir := item.Item.Path
+		if size, ok := s.sizes[dir]; ok {
+			in.reports[i].size, in.reports[i].sized = size, true
+		} else {
+			in.pendingSize++
+			go func() {
+				send(reportUpdate{index: i, sized: true, report: tryReport{size: dirSize(ctx, dir)}})
+			}()
+		}
+		in.pendingGit++
+		go func() {
+			send(reportUpdate{index: i, report: checkGit(ctx, dir)})
+		}()
+	}
+	return in
+}
+
+// poll applies the results that arrived without waiting for more
+func (in *inspection) poll() {
+	for {
+		select {
+		case u := <-in.updates:
+			in.apply(u)
+		default:
+			return
+		}
+	}
+}
+
+// wait applies results until every try is measured and checked
+func (in *inspection) wait() {
+	for in.pendingGit > 0 || in.pendingSize > 0 {
+		in.apply(<-in.updates)
+	}
+}
+
+func (in *inspection) apply(u reportUpdate) {
+	r := &in.reports[u.index]
+	if u.sized {
+		r.size, r.sized = u.report.size, true
+		in.pendingSize--
+		return
+	}
+	size, sized := r.size, r.sized
+	*r = u.report
+	r.size, r.sized, r.checked = size, sized, true
+	in.pendingGit--
+}
+
+// unpushedTries counts the tries with commits that exist nowhere else
+func (in *inspection) unpushedTries() int {
+	n := 0
+	for _, r := range in.reports {
+		if r.unpushed > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// stop abandons the measurements still running
+func (in *inspection) stop() {
+	in.cancel()
+}
+
+// checkGit looks for work in dir that deleting it would lose. A repository
+// loses every commit not on a remote; a worktree only commits no branch,
+// tag or remote holds, since the rest live in its source repository.
+// Directories that are not repositories, or where git is missing, report
+// nothing.
+func checkGit(ctx context.Context, dir string) tryReport {
+	var r tryReport
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Lstat(dotGit)
+	if err != nil {
+		return r
+	}
+	revs := []string{"--all", "--not", "--remotes"}
+	if !info.IsDir() {
+		gitDir, ok := resolveGitDir(dotGit)
+		if !ok {
+			return r
+		}
+		r.worktree, r.source = true, sourceRepo(gitDir)
+		if _, err := os.Stat(gitDir); err != nil {
+			r.sourceGone = true
+			return r
+		}
+		revs = []string{"HEAD", "--not", "--branches", "--tags", "--remotes"}
+	}
+
+	if out, err := runGit(ctx, dir, "status", "--porcelain"); err == nil {
+		r.dirty = out != ""
+	}
+	if out, err := runGit(ctx, dir, append([]string{"rev-list", "--count"}, revs...)...); err == nil {
+		r.unpushed, _ = strconv.Atoi(out)
+	}
+	return r
+}
+
+// sourceRepo returns the repository a worktree's git directory belongs
+// to, from the common directory it names
+func sourceRepo(gitDir string) string {
+	common := filepath.Dir(filepath.Dir(gitDir))
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+	}
+	if filepath.Base(common) == ".git" {
+		return filepath.Dir(common)
+	}
+	return common
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// reportDetails describes a report for the delete dialog, with what would
+// be lost highlighted
+func (s *Selector) reportDetails(r tryReport) string {
+	size := "measuring…"
+	if r.sized {
+		size = FormatSize(r.size)
+	}
+	parts := []string{dim(size)}
+	switch {
+	case !r.checked:
+		parts = append(parts, dim("checking git…"))
+	case r.sourceGone:
+		parts = append(parts, highlight("worktree, source repo missing"))
+	case r.worktree:
+		parts = append(parts, dim("worktree of "+abbreviateHome(r.source)))
+	}
+	if r.dirty {
+		parts = append(parts, highlight("uncommitted changes"))
+	}
+	if r.unpushed == 1 {
+		parts = append(parts, highlight("1 unpushed commit"))
+	} else if r.unpushed > 1 {
+		parts = append(parts, highlight(fmt.Sprintf("%d unpushed commits", r.unpushed)))
+	}
+	return strings.Join(parts, dim(", "))
+}
@@ -0,0 +1,471 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/amulcse/try/internal/config"
+)
+
+// Selection actions that can be bound to keys in the config
+const (
+	ActionCd    = "cd"
+	ActionEdit  = "edit"
+	ActionTmux  = "tmux"
+	ActionPrint = "print"
+)
+
+// Selector actions that keys are bound to
+const (
+	actMoveUp         = "move-up"
+	actMoveDown       = "move-down"
+	actMoveFirst      = "move-first"
+	actMoveLast       = "move-last"
+	actSelect         = "select"
+	actCreate         = "create"
+	actRename         = "rename"
+	actToggleMark     = "toggle-mark"
+	actDelete         = "delete"
+	actCancel         = "cancel"
+	actClearInput     = "clear-input"
+	actCycleSort      = "cycle-sort"
+	actCycleCase      = "cycle-case"
+	actToggleGrep     = "toggle-grep"
+	actLineStart      = "line-start"
+	actLineEnd        = "line-end"
+	actCharBack       = "char-back"
+	actCharForward    = "char-forward"
+	actDeleteCharBack = "delete-char-back"
+	actKillLine       = "kill-line"
+	actDeleteWordBack = "delete-word-back"
+	actNormalMode     = "normal-mode"
+	actInsertMode     = "insert-mode"
+	actHelp           = "help"
+)
+
+// keyActions lists every action that can be bound, in the order they are
+// shown
+var keyActions = []struct {
+	name string
+	desc string
+}{
+	{actMoveUp, "Move the selection up"},
+	{actMoveDown, "Move the selection down"},
+	{actMoveFirst, "Select the first entry"},
+	{actMoveLast, "Select the last entry"},
+	{actSelect, "Open the selection, or act on the marked entries"},
+	{actCreate, "Create a try named after the input"},
+	{actRename, "Rename the selection"},
+	{actToggleMark, "Mark or unmark the selection and move down"},
+	{actDelete, "Mark or unmark the selection for deletion"},
+	{actCancel, "Clear the marks, or leave the selector"},
+	{actClearInput, "Clear the input"},
+	{actCycleSort, "Cycle the sort mode"},
+	{actCycleCase, "Cycle the case mode"},
+	{actToggleGrep, "Search inside tries instead of their names"},
+	{actLineStart, "Move the cursor to the start of the input"},
+	{actLineEnd, "Move the cursor to the end of the input"},
+	{actCharBack, "Move the cursor back one character"},
+	{actCharForward, "Move the cursor forward one character"},
+	{actDeleteCharBack, "Delete the character before the cursor"},
+	{actKillLine, "Delete from the cursor to the end of the input"},
+	{actDeleteWordBack, "Delete the word before the cursor"},
+	{actNormalMode, "Stop typing and take single-key commands"},
+	{actInsertMode, "Go back to typing"},
+	{actHelp, "Show the key bindings and query syntax"},
+	{ActionCd, "cd into the selection"},
+	{ActionEdit, "Open the selection in $EDITOR"},
+	{ActionTmux, "Open the selection in a tmux session"},
+	{ActionPrint, "Print the selection's path"},
+}
+
+// knownAction reports whether name is an action keys can be bound to
+func knownAction(name string) bool {
+	for _, a := range keyActions {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var namedKeys = map[string]string{
+	"enter":     "\r",
+	"alt-enter": "\x1b\r",
+	"tab":       "\t",
+	"shift-tab": "\x1b[Z",
+	"esc":       "\x1b",
+	"space":     " ",
+	"up":        "\x1b[A",
+	"down":      "\x1b[B",
+	"right":     "\x1b[C",
+	"left":      "\x1b[D",
+	"home":      keyHome,
+	"end":       keyEnd,
+	"delete":    "\x1b[3~",
+	"pgup":      "\x1b[5~",
+	"pgdn":      "\x1b[6~",
+	"backspace": "\x7f",
+	"f1":        "\x1bOP",
+}
+
+// KeySequence returns the bytes the terminal sends for a key name such as
+// "ctrl-o", "alt-x", "alt-enter" or a single character like "j"
+func KeySequence(name string) (string, bool) {
+	if r, ok := typedRune(name); ok && r > ' ' && r != 0x7f {
+		return name, true // case matters: "G" is not "g"
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if seq, ok := namedKeys[name]; ok {
+		return seq, true
+	}
+	if rest, ok := strings.CutPrefix(name, "ctrl-"); ok && len(rest) == 1 && rest[0] >= 'a' && rest[0] <= 'z' {
+		return string(rune(rest[0] - 'a' + 1)), true
+	}
+	if rest, ok := strings.CutPrefix(name, "alt-"); ok && len(rest) == 1 && rest[0] >= 0x21 && rest[0] < 0x7f {
+		return "\x1b" + rest, true
+	}
+	return "", false
+}
+
+// keyName returns the name of a key sequence, as KeySequence takes it
+func keyName(seq string) string {
+	for name, s := range namedKeys {
+		if s == seq {
+			return name
+		}
+	}
+	switch {
+	case len(seq) == 1 && seq[0] >= 1 && seq[0] <= 26:
+		return "ctrl-" + string(rune(seq[0]-1+'a'))
+	case len(seq) == 2 && seq[0] == 0x1b:
+		return "alt-" + seq[1:]
+	}
+	return seq
+}
+
+// keyLabels are how keys are shown in hints
+var keyLabels = map[string]string{
+	"enter":     "Enter",
+	"alt-enter": "Alt-Enter",
+	"tab":       "Tab",
+	"shift-tab": "Shift-Tab",
+	"esc":       "Esc",
+	"space":     "Space",
+	"up":        "↑",
+	"down":      "↓",
+	"right":     "→",
+	"left":      "←",
+	"home":      "Home",
+	"end":       "End",
+	"delete":    "Del",
+	"pgup":      "PgUp",
+	"pgdn":      "PgDn",
+	"backspace": "Backspace",
+	"f1":        "F1",
+}
+
+// keyLabel returns how a key is shown in hints: "Ctrl-D", or "^D" when
+// short
+func keyLabel(seq string, short bool) string {
+	name := keyName(seq)
+	if label, ok := keyLabels[name]; ok {
+		return label
+	}
+	if rest, ok := strings.CutPrefix(name, "ctrl-"); ok {
+		if short {
+			return "^" + strings.ToUpper(rest)
+		}
+		return "Ctrl-" + strings.ToUpper(rest)
+	}
+	if rest, ok := strings.CutPrefix(name, "alt-"); ok {
+		return "Alt-" + strings.ToUpper(rest)
+	}
+	return name
+}
+
+// Modes of the keymap. Every preset has an insert mode, where printable
+// keys type; the vim preset adds a normal mode of single-key commands.
+const (
+	modeInsert = "insert"
+	modeNormal = "normal"
+)
+
+// bindings maps key sequences to actions and remembers the order keys
+// were bound in, which is the order hints prefer them in
+type bindings struct {
+	order  []string
+	action map[string]string
+}
+
+func newBindings(pairs ...string) *bindings {
+	b := &bindings{action: map[string]string{}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		seq, _ := KeySequence(pairs[i])
+		b.bind(seq, pairs[i+1])
+	}
+	return b
+}
+
+func (b *bindings) bind(seq, action string) {
+	if _, ok := b.action[seq]; !ok {
+		b.order = append(b.order, seq)
+	}
+	b.action[seq] = action
+}
+
+func (b *bindings) unbind(seq string) {
+	if _, ok := b.action[seq]; !ok {
+		return
+	}
+	delete(b.action, seq)
+	for i, s := range b.order {
+		if s == seq {
+			b.order = append(b.order[:i], b.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// keys returns the keys bound to action, in binding order
+func (b *bindings) keys(action string) []string {
+	var keys []string
+	for _, seq := range b.order {
+		if b.action[seq] == action {
+			keys = append(keys, seq)
+		}
+	}
+	return keys
+}
+
+// Keymap maps keys to selector actions in each mode
+type Keymap struct {
+	Preset string
+	modes  map[string]*bindings
+}
+
+// emacsKeys are the bindings of the emacs preset, and of the vim preset's
+// insert mode except for Esc
+var emacsKeys = []string{
+	"up", actMoveUp, "ctrl-p", actMoveUp,
+	"down", actMoveDown, "ctrl-n", actMoveDown,
+	"enter", actSelect, "ctrl-j", actSelect,
+	"tab", actToggleMark,
+	"ctrl-r", actRename,
+	"ctrl-d", actDelete,
+	"esc", actCancel, "ctrl-c", actCancel,
+	"ctrl-t", actCreate,
+	"ctrl-u", actClearInput,
+	"ctrl-s", actCycleSort,
+	"ctrl-g", actCycleCase,
+	"alt-g", actToggleGrep,
+	"ctrl-a", actLineStart,
+	"ctrl-e", actLineEnd,
+	"ctrl-b", actCharBack,
+	"ctrl-f", actCharForward,
+	"backspace", actDeleteCharBack, "ctrl-h", actDeleteCharBack,
+	"ctrl-k", actKillLine,
+	"ctrl-w", actDeleteWordBack,
+	"f1", actHelp, "?", actHelp,
+}
+
+// vimNormalKeys are the bindings of the vim preset's normal mode
+var vimNormalKeys = []string{
+	"j", actMoveDown, "down", actMoveDown, "ctrl-n", actMoveDown,
+	"k", actMoveUp, "up", actMoveUp, "ctrl-p", actMoveUp,
+	"g", actMoveFirst, "G", actMoveLast,
+	"enter", actSelect, "ctrl-j", actSelect,
+	"space", actToggleMark, "tab", actToggleMark,
+	"r", actRename,
+	"d", actDelete,
+	"q", actCancel, "esc", actCancel, "ctrl-c", actCancel,
+	"i", actInsertMode, "a", actInsertMode, "/", actInsertMode,
+	"c", actCreate,
+	"x", actClearInput,
+	"s", actCycleSort,
+	"ctrl-g", actCycleCase,
+	"alt-g", actToggleGrep,
+	"?", actHelp, "f1", actHelp,
+}
+
+// NewKeymap builds the keymap of cfg: its preset, then the selection
+// actions of cfg.Actions, then cfg.Keys. Bindings that name an unknown key,
+// action or mode are skipped and reported.
+func NewKeymap(cfg *config.Config) (*Keymap, []error) {
+	km := &Keymap{Preset: cfg.Keymap, modes: map[string]*bindings{}}
+	km.modes[modeInsert] = newBindings(emacsKeys...)
+	if cfg.Keymap == config.KeymapVim {
+		km.modes[modeInsert].bind("\x1b", actNormalMode)
+		km.modes[modeNormal] = newBindings(vimNormalKeys...)
+	}
+
+	var errs []error
+	for _, name := range sortedKeys(cfg.Actions) {
+		action := cfg.Actions[name]
+		switch action {
+		case ActionCd, ActionEdit, ActionTmux, ActionPrint, "":
+		default:
+			errs = append(errs, fmt.Errorf("actions: %s: unknown action %q", name, action))
+			continue
+		}
+		if err := km.apply(name, action); err != nil {
+			errs = append(errs, fmt.Errorf("actions: %w", err))
+		}
+	}
+	for _, name := range sortedKeys(cfg.Keys) {
+		action := cfg.Keys[name]
+		if action != "" && !knownAction(action) {
+			errs = append(errs, fmt.Errorf("keys: %s: unknown action %q", name, action))
+			continue
+		}
+		if err := km.apply(name, action); err != nil {
+			errs = append(errs, fmt.Errorf("keys: %w", err))
+		}
+	}
+	return km, errs
+}
+
+// apply binds a configured key, "mode:key" or "key" for every mode, to
+// action, or unbinds it when action is empty
+func (km *Keymap) apply(name, action string) error {
+	modes := []string{modeInsert, modeNormal}
+	key := name
+	if mode, rest, ok := strings.Cut(name, ":"); ok && rest != "" {
+		if _, exists := km.modes[mode]; !exists {
+			return fmt.Errorf("%s: no %s mode in the %s keymap", name, mode, km.Preset)
+		}
+		modes, key = []string{mode}, rest
+	}
+	seq, ok := KeySequence(key)
+	if !ok {
+		return fmt.Errorf("%s: unknown key", name)
+	}
+	for _, mode := range modes {
+		b := km.modes[mode]
+		if b == nil {
+			continue
+		}
+		if action == "" {
+			b.unbind(seq)
+		} else {
+			b.bind(seq, action)
+		}
+	}
+	return nil
+}
+
+// action returns the action key is bound to in mode, or ""
+func (km *Keymap) action(mode, key string) string {
+	if b := km.modes[mode]; b != nil {
+		return b.action[key]
+	}
+	return ""
+}
+
+// label returns the hint label of the first key bound to action in mode,
+// or "" when none is
+func (km *Keymap) label(mode, action string, short bool) string {
+	if b := km.modes[mode]; b != nil {
+		if keys := b.keys(action); len(keys) > 0 {
+			return keyLabel(keys[0], short)
+		}
+	}
+	return ""
+}
+
+// hints returns "key: text" for each action and text pair whose action has
+// a key in mode, e.g. "Enter: Select" for actSelect, "Select"
+func (km *Keymap) hints(mode string, short bool, pairs ...string) []string {
+	var hints []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if label := km.label(mode, pairs[i], short); label != "" {
+			hints = append(hints, label+": "+pairs[i+1])
+		}
+	}
+	return hints
+}
+
+// footerHints returns the key hints of the list footer in the current mode
+func (s *Selector) footerHints() string {
+	km, mode := s.keymap, s.mode
+	var hints []string
+	if up, down := km.label(mode, actMoveUp, true), km.label(mode, actMoveDown, true); up != "" && down != "" {
+		hints = append(hints, up+"/"+down+": Navigate")
+	}
+	switchMode, switchText := actNormalMode, "Normal"
+	if mode == modeNormal {
+		switchMode, switchText = actInsertMode, "Insert"
+	}
+	hints = append(hints, km.hints(mode, true,
+		actSelect, "Select", actToggleMark, "Mark", actRename, "Rename", actDelete, "Delete",
+		switchMode, switchText, actCancel, "Cancel")...)
+	return strings.Join(hints, "  ")
+}
+
+// headerHints returns the case and sort modes shown in the header, each
+// with the key that cycles it
+func (s *Selector) headerHints() string {
+	parts := []string{"Case: " + s.caseMode, "Sort: " + s.sortMode}
+	for i, action := range []string{actCycleCase, actCycleSort} {
+		if label := s.keymap.label(s.mode, action, true); label != "" {
+			parts[i] += " (" + label + ")"
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// dialogHints returns the key hints of a dialog: confirm and cancel
+func (s *Selector) dialogHints() string {
+	cancel := s.keymap.label(modeInsert, actNormalMode, false)
+	if cancel == "" {
+		cancel = s.keymap.label(modeInsert, actCancel, false)
+	}
+	hints := s.keymap.hints(modeInsert, false, actSelect, "Confirm")
+	if cancel != "" {
+		hints = append(hints, cancel+": Cancel")
+	}
+	return strings.Join(hints, "  ")
+}
+
+// markHints returns the key hints of mark and delete mode: toggle is the
+// action that toggles marks there, and selecting does what
+func (s *Selector) markHints(toggle, selectText string) string {
+	return strings.Join(s.keymap.hints(s.mode, false, toggle, "Toggle", actSelect, selectText, actCancel, "Cancel"), "  ")
+}
+
+// Describe writes the bindings of every mode, one action per line
+func (km *Keymap) Describe(w io.Writer) {
+	fmt.Fprintf(w, "Keymap: %s\n", km.Preset)
+	for _, mode := range []string{modeInsert, modeNormal} {
+		b := km.modes[mode]
+		if b == nil {
+			continue
+		}
+		if km.modes[modeNormal] != nil {
+			fmt.Fprintf(w, "\n%s mode:\n", strings.ToUpper(mode[:1])+mode[1:])
+		}
+		fmt.Fprintln(w)
+		for _, a := range keyActions {
+			keys := b.keys(a.name)
+			if len(keys) == 0 {
+				continue
+			}
+			names := make([]string, len(keys))
+			for i, seq := range keys {
+				names[i] = keyName(seq)
+			}
+			fmt.Fprintf(w, "  %-18s %-24s %s\n", a.name, strings.Join(names, ", "), a.desc)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
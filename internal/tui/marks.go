@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/amulcse/try/internal/config"
+)
+
+// Bulk actions offered for marked tries
+const (
+	BulkDelete  = "delete"
+	BulkArchive = "archive"
+	BulkTag     = "tag"
+	BulkMove    = "move"
+	BulkExport  = "export"
+)
+
+// bulkMenu lists the action menu entries in footer order
+var bulkMenu = []struct {
+	key    string
+	action string
+	label  string
+}{
+	{"d", BulkDelete, "Delete"},
+	{"a", BulkArchive, "Archive"},
+	{"t", BulkTag, "Tag"},
+	{"m", BulkMove, "Move"},
+	{"e", BulkExport, "Export"},
+}
+
+func (s *Selector) toggleMark(path string) {
+	if idx := indexOf(s.marked, path); idx >= 0 {
+		s.marked = append(s.marked[:idx], s.marked[idx+1:]...)
+	} else {
+		s.marked = append(s.marked, path)
+	}
+	if len(s.marked) == 0 {
+		s.clearMarks()
+	}
+}
+
+func (s *Selector) clearMarks() {
+	s.marked = nil
+	s.deleteMode = false
+	s.markMode = false
+	s.actionMenu = false
+}
+
+// markedEntries returns the marked tries among tries, in list order
+func (s *Selector) markedEntries(tries []Entry) []Entry {
+	items := []Entry{}
+	for _, t := range tries {
+		if indexOf(s.marked, t.Item.Path) >= 0 {
+			items = append(items, t)
+		}
+	}
+	return items
+}
+
+// markedPaths resolves items for a bulk action, refusing anything that
+// resolves outside the tries directory. On failure it sets the status line.
+func (s *Selector) markedPaths(items []Entry) ([]MarkedPath, string, bool) {
+	baseReal, err := filepath.EvalSymlinks(s.basePath)
+	if err != nil {
+		baseReal = s.basePath
+	}
+
+	paths := []MarkedPath{}
+	for _, item := range items {
+		targetReal, err := filepath.EvalSymlinks(item.Item.Path)
+		if err != nil {
+			targetReal = item.Item.Path
+		}
+		if !strings.HasPrefix(targetReal, baseReal+"/") {
+			s.setStatus(fmt.Sprintf("Safety check failed: %s not in %s", targetReal, baseReal))
+			return nil, "", false
+		}
+		paths = append(paths, MarkedPath{
+			Path:     targetReal,
+			Basename: item.Item.Text,
+		})
+	}
+	return paths, baseReal, true
+}
+
+func (s *Selector) renderMarkModeFooter() string {
+	var out strings.Builder
+	out.WriteString(tokenStart("marked"))
+	out.WriteString(bold(" MARK MODE "))
+	out.WriteString(fmt.Sprintf(" %d marked  |  %s", len(s.marked), s.markHints(actToggleMark, "Actions")))
+	return out.String()
+}
+
+func (s *Selector) renderActionMenuFooter() string {
+	var out strings.Builder
+	out.WriteString(tokenStart("marked"))
+	out.WriteString(bold(fmt.Sprintf(" %d marked ", len(s.marked))))
+	out.WriteString(" ")
+	for _, entry := range bulkMenu {
+		out.WriteString(fmt.Sprintf(" %s: %s ", entry.key, entry.label))
+	}
+	out.WriteString(" Esc: Back")
+	return out.String()
+}
+
+// handleActionMenu runs the bulk action chosen by key on the marked tries
+func (s *Selector) handleActionMenu(key string, tries []Entry) {
+	if s.dialogAction(key) == actCancel {
+		s.actionMenu = false
+		return
+	}
+
+	action := ""
+	for _, entry := range bulkMenu {
+		if entry.key == strings.ToLower(key) {
+			action = entry.action
+		}
+	}
+	if action == "" {
+		return
+	}
+	s.actionMenu = false
+
+	items := s.markedEntries(tries)
+	if len(items) == 0 {
+		return
+	}
+
+	result := &SelectionResult{Type: action}
+	switch action {
+	case BulkDelete:
+		s.deleteMode, s.markMode = true, false
+		s.confirmBatchDelete(tries)
+		return
+
+	case BulkTag:
+		input, ok := s.runPromptDialog(s.icon("🏷️", ""), "Tag", items, "Tags: ", "")
+		if !ok {
+			return
+		}
+		result.Tags = input
+
+	case BulkMove:
+		input, ok := s.runPromptDialog(s.icon("📦", ""), "Move", items, "Move to: ", "")
+		if !ok {
+			return
+		}
+		target := config.ExpandPath(strings.TrimSpace(input))
+		if target == "" {
+			s.setStatus("Move cancelled")
+			return
+		}
+		targetReal, _ := filepath.EvalSymlinks(target)
+		baseReal, _ := filepath.EvalSymlinks(s.basePath)
+		if target == s.basePath || (targetReal != "" && targetReal == baseReal) {
+			s.setStatus("Move target is the current tries directory")
+			return
+		}
+		result.Target = target
+	}
+
+	paths, baseReal, ok := s.markedPaths(items)
+	if !ok {
+		return
+	}
+	result.Paths = paths
+	result.BasePath = baseReal
+	s.selected = result
+	s.clearMarks()
+}
+
+// runPromptDialog asks for one line of input about the marked items. It
+// returns false when the user cancels.
+func (s *Selector) runPromptDialog(icon, title string, items []Entry, label, initial string) (string, bool) {
+	buffer := initial
+	cursor := len(buffer)
+
+	for {
+		s.renderPromptDialog(icon, title, items, label, buffer, cursor)
+
+		key := s.readKey()
+		switch s.dialogAction(key) {
+		case actSelect:
+			return buffer, true
+		case actCancel:
+			s.NeedsRedraw = true
+			return "", false
+		default:
+			buffer, cursor, _ = s.editLine(buffer, cursor, key, isPromptPrintable)
+		}
+	}
+}
+
+func (s *Selector) renderPromptDialog(icon, title string, items []Entry, label, buffer string, cursor int) {
+	var out strings.Builder
+	out.WriteString(s.home())
+
+	count := len(items)
+	plural := "directories"
+	if count == 1 {
+		plural = "directory"
+	}
+
+	// Header
+	header := s.centerText(icon + accent(fmt.Sprintf("  %s %d %s", title, count, plural)))
+	out.WriteString("\r" + ansiClearEOL + header + "\n")
+	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
+
+	// List items
+	for _, item := range items {
+		out.WriteString("\r" + ansiClearEOL + s.icon("📌", "*") + " " + item.Item.Text + "\n")
+	}
+
+	// Blank lines
+	out.WriteString("\r" + ansiClearEOL + "\n")
+	out.WriteString("\r" + ansiClearEOL + "\n")
+
+	// Input prompt
+	prompt := s.centerText(dim(label) + s.renderInput(buffer, cursor))
+	out.WriteString("\r" + ansiClearEOL + prompt + "\n")
+
+	// Fill remaining space
+	usedLines := 2 + len(items) + 3 + 2 // header + items + blanks + prompt + footer
+	for i := usedLines; i < s.height-2; i++ {
+		out.WriteString("\r" + ansiClearEOL + "\n")
+	}
+
+	// Footer
+	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
+	out.WriteString("\r" + ansiClearEOL + s.centerText(dim(s.dialogHints())))
+
+	out.WriteString(ansiShow)
+	out.WriteString(ansiReset)
+
+	s.io.WriteString(out.String())
+}
+
+func isPromptPrintable(r rune) bool {
+	return r >= 0x20 && unicode.IsPrint(r)
+}
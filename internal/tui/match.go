@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amulcse/try/internal/fuzzy"
+	"github.com/amulcse/try/internal/grep"
+)
+
+// matchWait is how long a frame waits for the matcher before it renders
+// the previous results; the finished match triggers another render
+const matchWait = 15 * time.Millisecond
+
+// pendingMatch is a match running in the background for query
+type pendingMatch struct {
+	query  string
+	cancel context.CancelFunc
+	done   chan matchResult
+}
+
+// matchResult is what a finished match yields
+type matchResult struct {
+	matches  []fuzzy.Match
+	snippets map[string]grep.Hit // content search: first hit by try path
+}
+
+// currentMatches returns the matches for the current input. A query that
+// takes longer than matchWait keeps running in the background while the
+// previous results are shown; typing again cancels it.
+func (s *Selector) currentMatches() []fuzzy.Match {
+	query := s.inputBuffer
+	if s.matches != nil && s.matchesFor == query {
+		return s.matches
+	}
+
+	if s.pending == nil || s.pending.query != query {
+		s.cancelMatch()
+		s.pending = s.startMatch(query)
+	}
+
+	// Tests need the frame for the keys they sent, however long it takes
+	var timeout <-chan time.Time
+	if !s.testHadKeys && !s.testRenderOnce {
+		timeout = time.After(matchWait)
+	}
+	select {
+	case result := <-s.pending.done:
+		s.pending.cancel()
+		s.pending = nil
+		s.matches = result.matches
+		s.snippets = result.snippets
+		s.matchesFor = query
+	case <-timeout:
+	}
+
+	if s.matches == nil {
+		return []fuzzy.Match{}
+	}
+	return s.matches
+}
+
+func (s *Selector) startMatch(query string) *pendingMatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &pendingMatch{query: query, cancel: cancel, done: make(chan matchResult, 1)}
+	prepare := s.fuzzyMatch
+	if s.contentSearch {
+		prepare = s.grepMatch
+	}
+	run := prepare(query)
+	wake := s.wake
+	go func() {
+		result, err := run(ctx)
+		if err != nil {
+			return
+		}
+		p.done <- result
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}()
+	return p
+}
+
+// fuzzyMatch returns a function matching query against the names of tries
+func (s *Selector) fuzzyMatch(query string) func(context.Context) (matchResult, error) {
+	matcher := s.matcher
+	return func(ctx context.Context) (matchResult, error) {
+		matches, err := matcher.MatchContext(ctx, query)
+		return matchResult{matches: matches}, err
+	}
+}
+
+// grepMatch returns a function searching inside tries for query. Tries
+// are ranked by their hits, which become the score.
+func (s *Selector) grepMatch(query string) func(context.Context) (matchResult, error) {
+	dirs := make([]string, len(s.allTries))
+	for i, t := range s.allTries {
+		dirs[i] = t.Path
+	}
+	opts := grep.Options{Contents: s.config.Grep.Contents}
+	return func(ctx context.Context) (matchResult, error) {
+		if strings.TrimSpace(query) == "" {
+			return matchResult{matches: []fuzzy.Match{}}, nil
+		}
+		results, err := grep.Search(ctx, dirs, query, opts)
+		if err != nil {
+			return matchResult{}, err
+		}
+		r := matchResult{
+			matches:  make([]fuzzy.Match, len(results)),
+			snippets: make(map[string]grep.Hit, len(results)),
+		}
+		for i, res := range results {
+			r.matches[i] = fuzzy.Match{
+				Entry:     fuzzy.Item{Path: res.Path},
+				Index:     res.Index,
+				Positions: []int{},
+				Score:     float64(res.Hits),
+			}
+			r.snippets[res.Path] = res.First
+		}
+		return r, nil
+	}
+}
+
+// cancelMatch stops the background match, if any
+func (s *Selector) cancelMatch() {
+	if s.pending != nil {
+		s.pending.cancel()
+		s.pending = nil
+	}
+}
+
+// resetMatches drops the matcher and its results after the list changed
+func (s *Selector) resetMatches() {
+	s.cancelMatch()
+	s.matcher = nil
+	s.matches = nil
+	s.snippets = nil
+	s.matchesFor = ""
+}
+
+// nextCaseMode returns the case mode after mode in fuzzy.CaseModes
+func nextCaseMode(mode string) string {
+	for i, m := range fuzzy.CaseModes {
+		if m == mode {
+			return fuzzy.CaseModes[(i+1)%len(fuzzy.CaseModes)]
+		}
+	}
+	return fuzzy.CaseSmart
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SGR mouse reporting: button presses and releases, including the wheel,
+// reported as ESC [ < button ; column ; row M (press) or m (release)
+const (
+	ansiMouseOn  = "\x1b[?1000h\x1b[?1006h"
+	ansiMouseOff = "\x1b[?1006l\x1b[?1000l"
+)
+
+// doubleClick is the longest gap between two clicks on the same entry
+// that opens it
+const doubleClick = 400 * time.Millisecond
+
+// wheelLines is how far one notch of the wheel moves the selection
+const wheelLines = 3
+
+// Button bits of an SGR mouse report
+const (
+	mouseButtons = 3  // 0 left, 1 middle, 2 right
+	mouseMotion  = 32 // reported while a button is held and moved
+	mouseWheel   = 64 // set for the wheel, 66 and 67 scroll sideways
+
+	mouseWheelUp   = mouseWheel
+	mouseWheelDown = mouseWheel + 1
+)
+
+type mouseEvent struct {
+	button int
+	col    int // 1-based
+	row    int // 1-based
+	press  bool
+}
+
+// parseMouse decodes an SGR mouse report
+func parseMouse(key string) (mouseEvent, bool) {
+	rest, ok := strings.CutPrefix(key, "\x1b[<")
+	if !ok || len(rest) < 2 {
+		return mouseEvent{}, false
+	}
+	final := rest[len(rest)-1]
+	if final != 'M' && final != 'm' {
+		return mouseEvent{}, false
+	}
+	fields := strings.Split(rest[:len(rest)-1], ";")
+	if len(fields) != 3 {
+		return mouseEvent{}, false
+	}
+	var nums [3]int
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return mouseEvent{}, false
+		}
+		nums[i] = n
+	}
+	return mouseEvent{button: nums[0], col: nums[1], row: nums[2], press: final == 'M'}, true
+}
+
+// handleMouse applies a mouse event to the list: a click selects the entry
+// under it, a second click on it opens it unless entries are marked, and
+// the wheel moves the selection, which scrolls the list to keep it
+// visible. It returns the key the event stands for, if any.
+func (s *Selector) handleMouse(ev mouseEvent) string {
+	switch {
+	case ev.button&mouseWheel != 0:
+		switch ev.button {
+		case mouseWheelUp:
+			s.cursorPos -= wheelLines
+		case mouseWheelDown:
+			s.cursorPos += wheelLines
+		default:
+			return "" // horizontal wheel, modified scrolls
+		}
+		s.lastClick = -1
+		return ""
+
+	case ev.button&mouseButtons == 0 && ev.button&mouseMotion == 0 && ev.press:
+		idx := s.entryAt(ev.row)
+		if idx < 0 {
+			return ""
+		}
+		now := time.Now()
+		double := idx == s.lastClick && now.Sub(s.lastClickAt) < doubleClick
+		s.cursorPos = idx
+		// With marks, Enter acts on them, which a double click never means
+		if double && len(s.marked) == 0 && !s.deleteMode {
+			s.lastClick = -1
+			return "\r"
+		}
+		s.lastClick, s.lastClickAt = idx, now
+	}
+	return ""
+}
+
+// entryAt returns the index of the entry drawn on a terminal row of the
+// last frame, or -1 for rows without one. Inline, rows count from the
+// region's top, which must be known.
+func (s *Selector) entryAt(row int) int {
+	if s.inline {
+		if s.regionTop == 0 {
+			return -1
+		}
+		row -= s.regionTop - 1
+	}
+	i := row - s.listTop
+	if i < 0 || i >= len(s.listRows) {
+		return -1
+	}
+	return s.listRows[i]
+}
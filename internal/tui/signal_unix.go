@@ -6,6 +6,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 // SetupResizeHandler sets up a handler for terminal resize events (Unix only)
@@ -18,3 +21,28 @@ func SetupResizeHandler(callback func()) {
 		}
 	}()
 }
+
+// inputInterruptible reports whether stopInput can wake the input reader
+const inputInterruptible = true
+
+// inputReady reports whether fd has input within timeout
+func inputReady(fd int, timeout time.Duration) bool {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	return err == nil && n > 0
+}
+
+// waitInput waits for input on fd and reports whether there is some. It
+// gives up once wake is readable, which closing its write end makes it, or
+// after inputPoll without a wake pipe.
+func waitInput(fd int, wake *os.File) bool {
+	if wake == nil {
+		return inputReady(fd, inputPoll)
+	}
+	fds := []unix.PollFd{
+		{Fd: int32(fd), Events: unix.POLLIN},
+		{Fd: int32(wake.Fd()), Events: unix.POLLIN},
+	}
+	n, err := unix.Poll(fds, -1)
+	return err == nil && n > 0 && fds[1].Revents == 0 && fds[0].Revents != 0
+}
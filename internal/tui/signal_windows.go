@@ -2,7 +2,26 @@
 
 package tui
 
+import (
+	"os"
+	"time"
+)
+
 // SetupResizeHandler is a no-op on Windows as SIGWINCH is not supported
 func SetupResizeHandler(callback func()) {
 	// No-op on Windows - terminal resize handling not supported
 }
+
+// inputInterruptible reports whether stopInput can wake the input reader;
+// console reads on Windows cannot be interrupted
+const inputInterruptible = false
+
+// inputReady always reports input on Windows, where reads simply block
+func inputReady(fd int, timeout time.Duration) bool {
+	return true
+}
+
+// waitInput always reports input on Windows, where reads simply block
+func waitInput(fd int, wake *os.File) bool {
+	return true
+}
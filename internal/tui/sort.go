@@ -0,0 +1,239 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amulcse/try/internal/config"
+	"github.com/amulcse/try/internal/index"
+)
+
+var datePrefixRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-`)
+
+// createdTime returns when a try was created: the date in its name when it
+// has one, since that is when try made it, else its modification time
+func createdTime(name string, mtime time.Time) time.Time {
+	if m := datePrefixRe.FindStringSubmatch(name); m != nil {
+		if t, err := time.ParseInLocation("2006-01-02", m[1], time.Local); err == nil {
+			return t
+		}
+	}
+	return mtime
+}
+
+// sortName is the name compared when sorting by name: the part after the
+// date prefix, ignoring case
+func sortName(name string) string {
+	return strings.ToLower(datePrefixRe.ReplaceAllString(name, ""))
+}
+
+// nextSortMode returns the mode after mode in cycle order
+func nextSortMode(mode string) string {
+	for i, m := range config.SortModes {
+		if m == mode {
+			return config.SortModes[(i+1)%len(config.SortModes)]
+		}
+	}
+	return config.SortScore
+}
+
+// sortEntries orders entries by the active sort mode. Score mode keeps the
+// matcher's order; other modes break ties by score, then by path, so the
+// order never depends on how the directory was read.
+func (s *Selector) sortEntries(entries []Entry) {
+	if s.sortMode == config.SortScore {
+		return
+	}
+	if s.sortMode == config.SortSize {
+		s.loadSizes()
+		for i := range entries {
+			entries[i].Item.Size = s.sizes[entries[i].Item.Path]
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Item, entries[j].Item
+		switch s.sortMode {
+		case config.SortRecent:
+			if !a.Mtime.Equal(b.Mtime) {
+				return a.Mtime.After(b.Mtime)
+			}
+		case config.SortOldest:
+			if !a.Mtime.Equal(b.Mtime) {
+				return a.Mtime.Before(b.Mtime)
+			}
+		case config.SortName:
+			if an, bn := sortName(a.Basename), sortName(b.Basename); an != bn {
+				return an < bn
+			}
+		case config.SortSize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case config.SortCreated:
+			if !a.Ctime.Equal(b.Ctime) {
+				return a.Ctime.After(b.Ctime)
+			}
+		}
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return a.Text < b.Text
+	})
+}
+
+// sizeWorkers is how many tries are measured at once
+const sizeWorkers = 8
+
+// sizing measures tries in the background for size sorting
+type sizing struct {
+	results  chan sizeResult
+	pending  int
+	measured map[string]int64 // by index path, saved once all are in
+	cancel   context.CancelFunc
+}
+
+type sizeResult struct {
+	item Item
+	size int64
+}
+
+// loadSizes starts measuring the disk usage of every try the first time
+// size sorting is needed; the list reload after a mutation clears it.
+// Sizes the index kept for unmodified tries are used at once until they
+// expire; the rest arrive through pollSizes and are saved when all are in.
+func (s *Selector) loadSizes() {
+	if s.sizes != nil {
+		return
+	}
+	s.sizes = make(map[string]int64, len(s.allTries))
+	cached := map[string]index.Entry{}
+	if s.index != nil {
+		cached = s.index.ByPath()
+	}
+	now := time.Now()
+	jobs := make(chan Item, len(s.allTries))
+	for _, t := range s.allTries {
+		if c, ok := cached[t.Text]; ok && c.SizeFresh(now) {
+			s.sizes[t.Path] = c.Size
+			continue
+		}
+		jobs <- t
+	}
+	close(jobs)
+	if len(jobs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &sizing{
+		results:  make(chan sizeResult, len(jobs)),
+		pending:  len(jobs),
+		measured: map[string]int64{},
+		cancel:   cancel,
+	}
+	wake := s.wake
+	for i := 0; i < min(sizeWorkers, len(jobs)); i++ {
+		go func() {
+			for t := range jobs {
+				size := dirSize(ctx, t.Path)
+				if ctx.Err() != nil {
+					return
+				}
+				m.results <- sizeResult{item: t, size: size}
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+	s.sizing = m
+
+	// Tests need the order the keys they send act on
+	if s.testHadKeys || s.testRenderOnce {
+		for s.sizing != nil {
+			s.applySize(<-m.results)
+		}
+	}
+}
+
+// pollSizes applies the sizes measured so far, keeping the cursor on its
+// try as the list reorders
+func (s *Selector) pollSizes() {
+	if s.sizing == nil || len(s.sizing.results) == 0 {
+		return
+	}
+	if tries := s.getTries(); s.cursorPos < len(tries) {
+		s.follow = tries[s.cursorPos].Item.Path
+	}
+	for s.sizing != nil && len(s.sizing.results) > 0 {
+		s.applySize(<-s.sizing.results)
+	}
+}
+
+func (s *Selector) applySize(r sizeResult) {
+	m := s.sizing
+	s.sizes[r.item.Path] = r.size
+	m.measured[r.item.Text] = r.size
+	m.pending--
+	if m.pending > 0 {
+		return
+	}
+	s.sizing = nil
+	if s.index != nil {
+		s.index.SetSizes(m.measured)
+		if s.prepareIndex() {
+			s.saveIndex(s.index)
+		}
+	}
+}
+
+// stopSizing abandons the measurements still running
+func (s *Selector) stopSizing() {
+	if s.sizing != nil {
+		s.sizing.cancel()
+		s.sizing = nil
+	}
+}
+
+// dirSize sums the sizes of the regular files below dir, stopping early
+// when ctx is cancelled
+func dirSize(ctx context.Context, dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// FormatSize formats a byte count with a binary unit, e.g. "1.5M"
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,30 @@
+package tui
+
+import "time"
+
+// statusTimeout is how long a status message stays in the footer
+const statusTimeout = 3 * time.Second
+
+// setStatus shows msg in place of the footer hints for statusTimeout
+func (s *Selector) setStatus(msg string) {
+	s.status = msg
+	s.statusUntil = time.Now().Add(statusTimeout)
+}
+
+// statusShown reports whether a status message is showing, clearing it
+// once it has timed out
+func (s *Selector) statusShown() bool {
+	if s.status != "" && !time.Now().Before(s.statusUntil) {
+		s.status = ""
+	}
+	return s.status != ""
+}
+
+// statusExpiry fires when the status message is due to go, so the footer
+// is redrawn without it
+func (s *Selector) statusExpiry() <-chan time.Time {
+	if s.status == "" {
+		return nil
+	}
+	return time.After(time.Until(s.statusUntil))
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is how many colors the terminal can show
+type ColorDepth int
+
+const (
+	Colors16 ColorDepth = iota
+	Colors256
+	ColorsTrue
+)
+
+// terms16 are terminals known to lack the 256-color palette
+var terms16 = []string{"linux", "vt100", "vt102", "vt220", "ansi", "cons25", "xterm-color"}
+
+// DetectColorDepth guesses the color depth from $COLORTERM and $TERM.
+// Terminals that say nothing are assumed to have the 256-color palette,
+// which nearly all current ones do.
+func DetectColorDepth(colorterm, term string) ColorDepth {
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return ColorsTrue
+	case strings.HasSuffix(term, "-direct") || strings.HasSuffix(term, "-truecolor"):
+		return ColorsTrue
+	case strings.Contains(term, "256color"):
+		return Colors256
+	case strings.HasSuffix(term, "-16color") || strings.HasSuffix(term, "-8color"):
+		return Colors16
+	}
+	for _, t := range terms16 {
+		if term == t {
+			return Colors16
+		}
+	}
+	return Colors256
+}
+
+// Text attributes with the SGR codes that turn them on and off
+var attributes = map[string][2]int{
+	"bold":      {1, 22},
+	"dim":       {2, 22},
+	"italic":    {3, 23},
+	"underline": {4, 24},
+	"reverse":   {7, 27},
+	"strike":    {9, 29},
+}
+
+// The 16 basic colors, by name and palette index
+var colorNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright-black", "bright-red", "bright-green", "bright-yellow",
+	"bright-blue", "bright-magenta", "bright-cyan", "bright-white",
+}
+
+// basicRGB approximates the basic colors as xterm draws them
+var basicRGB = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+type colorKind int
+
+const (
+	colorNone colorKind = iota
+	colorIndexed
+	colorRGB
+)
+
+// color is a palette index (0-255) or an RGB value
+type color struct {
+	kind  colorKind
+	index int
+	rgb   [3]int
+}
+
+// Style is a text style: attributes and a foreground and background color
+type Style struct {
+	attrs []string
+	fg    color
+	bg    color
+}
+
+// ParseStyle parses a style such as "bold yellow", "245 bg:#1d2021" or
+// "underline bright-blue": attribute names, then a foreground color and a
+// background color prefixed with "bg:". A color is one of the 16 names, a
+// 256-color palette index or #rrggbb.
+func ParseStyle(spec string) (Style, error) {
+	var st Style
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		if _, ok := attributes[word]; ok {
+			st.attrs = append(st.attrs, word)
+			continue
+		}
+		target := &st.fg
+		if rest, ok := strings.CutPrefix(word, "bg:"); ok {
+			target, word = &st.bg, rest
+		} else {
+			word = strings.TrimPrefix(word, "fg:")
+		}
+		c, err := parseColor(word)
+		if err != nil {
+			return Style{}, err
+		}
+		*target = c
+	}
+	return st, nil
+}
+
+func parseColor(word string) (color, error) {
+	if word == "gray" || word == "grey" {
+		word = "bright-black"
+	}
+	for i, name := range colorNames {
+		if word == name {
+			return color{kind: colorIndexed, index: i}, nil
+		}
+	}
+	if hex, ok := strings.CutPrefix(word, "#"); ok {
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return color{}, fmt.Errorf("bad color %q", word)
+		}
+		return color{kind: colorRGB, rgb: [3]int{int(n >> 16), int(n >> 8 & 0xff), int(n & 0xff)}}, nil
+	}
+	n, err := strconv.Atoi(word)
+	if err != nil || n < 0 || n > 255 {
+		return color{}, fmt.Errorf("unknown color or attribute %q", word)
+	}
+	return color{kind: colorIndexed, index: n}, nil
+}
+
+// start returns the escape sequences that turn the style on, one per
+// attribute and color, with colors reduced to what depth can show
+func (st Style) start(depth ColorDepth) string {
+	var b strings.Builder
+	for _, attr := range st.attrs {
+		fmt.Fprintf(&b, "\x1b[%dm", attributes[attr][0])
+	}
+	b.WriteString(st.fg.sgr(depth, 30))
+	b.WriteString(st.bg.sgr(depth, 40))
+	return b.String()
+}
+
+// end returns the escape sequences that turn the style off again, leaving
+// anything it did not set alone
+func (st Style) end() string {
+	var b strings.Builder
+	if st.fg.kind != colorNone {
+		b.WriteString("\x1b[39m")
+	}
+	if st.bg.kind != colorNone {
+		b.WriteString("\x1b[49m")
+	}
+	seen := map[int]bool{}
+	for _, attr := range st.attrs {
+		off := attributes[attr][1]
+		if !seen[off] {
+			fmt.Fprintf(&b, "\x1b[%dm", off)
+			seen[off] = true
+		}
+	}
+	return b.String()
+}
+
+// sgr returns the sequence selecting the color, base being 30 for the
+// foreground and 40 for the background
+func (c color) sgr(depth ColorDepth, base int) string {
+	switch {
+	case c.kind == colorNone:
+		return ""
+	case c.kind == colorRGB && depth == ColorsTrue:
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", base+8, c.rgb[0], c.rgb[1], c.rgb[2])
+	}
+
+	index := c.index
+	if c.kind == colorRGB {
+		index = nearest256(c.rgb)
+	}
+	if index >= 16 && depth == Colors16 {
+		index = nearestBasic(paletteRGB(index))
+	}
+	switch {
+	case index < 8:
+		return fmt.Sprintf("\x1b[%dm", base+index)
+	case index < 16:
+		return fmt.Sprintf("\x1b[%dm", base+60+index-8)
+	}
+	return fmt.Sprintf("\x1b[%d;5;%dm", base+8, index)
+}
+
+// paletteRGB returns the RGB value of a 256-color palette index
+func paletteRGB(index int) [3]int {
+	switch {
+	case index < 16:
+		return basicRGB[index]
+	case index < 232:
+		i := index - 16
+		return [3]int{cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]}
+	}
+	v := 8 + (index-232)*10
+	return [3]int{v, v, v}
+}
+
+// nearest256 returns the cube or grayscale palette entry closest to rgb
+func nearest256(rgb [3]int) int {
+	var cube [3]int
+	for i, v := range rgb {
+		cube[i] = nearestLevel(v)
+	}
+	best := 16 + cube[0]*36 + cube[1]*6 + cube[2]
+
+	avg := (rgb[0] + rgb[1] + rgb[2]) / 3
+	gray := 232 + (avg-3)/10
+	if gray < 232 {
+		gray = 232
+	} else if gray > 255 {
+		gray = 255
+	}
+	if distance(paletteRGB(gray), rgb) < distance(paletteRGB(best), rgb) {
+		return gray
+	}
+	return best
+}
+
+func nearestLevel(v int) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(level-v) < abs(cubeLevels[best]-v) {
+			best = i
+		}
+	}
+	return best
+}
+
+// nearestBasic returns the basic color closest to rgb
+func nearestBasic(rgb [3]int) int {
+	best := 0
+	for i, c := range basicRGB {
+		if distance(c, rgb) < distance(basicRGB[best], rgb) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(a, b [3]int) int {
+	d := 0
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/amulcse/try/internal/config"
+)
+
+// Theme presets
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// Theme maps the style tokens of spec/token_system.md to styles
+type Theme map[string]Style
+
+// presets are the built-in themes. Every theme sets these tokens; a theme
+// file overrides some of them.
+var presets = map[string]map[string]string{
+	ThemeDark: {
+		"b":        "bold yellow",
+		"dim":      "245",
+		"h1":       "bold 214",
+		"h2":       "bold blue",
+		"section":  "bold",
+		"strike":   "bg:52",
+		"selected": "bg:238",
+		"marked":   "bg:24",
+	},
+	ThemeLight: {
+		"b":        "bold 166",
+		"dim":      "243",
+		"h1":       "bold 130",
+		"h2":       "bold 25",
+		"section":  "bold",
+		"strike":   "bg:224",
+		"selected": "bg:254",
+		"marked":   "bg:153",
+	},
+}
+
+// themeFile is the format of a theme file:
+// {"preset": "light", "tokens": {"b": "bold #d75f00", "selected": "bg:#eeeeee"}}
+type themeFile struct {
+	Preset string            `json:"preset"`
+	Tokens map[string]string `json:"tokens"`
+}
+
+// LoadTheme returns the preset called spec, or reads the theme file at
+// spec, relative to the config file's directory. An empty spec is the dark
+// preset. On error the dark preset is returned with it.
+func LoadTheme(spec string) (Theme, error) {
+	if spec == "" {
+		spec = ThemeDark
+	}
+	if _, ok := presets[spec]; ok {
+		return presetTheme(spec), nil
+	}
+
+	path := spec
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+		path = filepath.Join(filepath.Dir(config.ConfigPath()), path)
+	}
+	path = config.ExpandPath(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return presetTheme(ThemeDark), err
+	}
+	var file themeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return presetTheme(ThemeDark), fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Preset == "" {
+		file.Preset = ThemeDark
+	}
+	if _, ok := presets[file.Preset]; !ok {
+		return presetTheme(ThemeDark), fmt.Errorf("%s: unknown preset %q", path, file.Preset)
+	}
+	theme := presetTheme(file.Preset)
+	for token, styleSpec := range file.Tokens {
+		if _, ok := theme[token]; !ok {
+			return presetTheme(ThemeDark), fmt.Errorf("%s: unknown token %q", path, token)
+		}
+		st, err := ParseStyle(styleSpec)
+		if err != nil {
+			return presetTheme(ThemeDark), fmt.Errorf("%s: %s: %w", path, token, err)
+		}
+		theme[token] = st
+	}
+	return theme, nil
+}
+
+func presetTheme(name string) Theme {
+	theme := Theme{}
+	for token, spec := range presets[name] {
+		st, err := ParseStyle(spec)
+		if err != nil {
+			panic(err) // the presets are fixed
+		}
+		theme[token] = st
+	}
+	return theme
+}
+
+var (
+	colorsEnabled = true
+	rawTokens     = false
+	activeTheme   = presetTheme(ThemeDark)
+	colorDepth    = Colors256
+)
+
+// DisableColors disables ANSI color output
+func DisableColors() {
+	colorsEnabled = false
+}
+
+// EnableColors enables ANSI color output
+func EnableColors() {
+	colorsEnabled = true
+}
+
+// ShowTokens writes style tokens such as {b} literally instead of
+// expanding them, so tests can check where styles go
+func ShowTokens() {
+	rawTokens = true
+}
+
+// SetTheme styles output with theme, reduced to the colors of depth
+func SetTheme(theme Theme, depth ColorDepth) {
+	activeTheme, colorDepth = theme, depth
+}
+
+// tokenStart returns the sequence a style token expands to
+func tokenStart(name string) string {
+	switch {
+	case rawTokens:
+		return "{" + name + "}"
+	case !colorsEnabled:
+		return ""
+	}
+	return activeTheme[name].start(colorDepth)
+}
+
+// tokenEnd returns the sequence that closes a style token. {/section}
+// resets everything; the others undo what their style set.
+func tokenEnd(name string) string {
+	switch {
+	case rawTokens:
+		return "{/" + name + "}"
+	case !colorsEnabled:
+		return ""
+	case name == "section":
+		return ansiReset
+	}
+	return activeTheme[name].end()
+}
+
+// styled wraps text in a style token
+func styled(name, text string) string {
+	if text == "" {
+		return text
+	}
+	return tokenStart(name) + text + tokenEnd(name)
+}
+
+var tokenRe = regexp.MustCompile(`\{/?[a-z0-9]+\}`)
+
+// Expand replaces the style tokens in text with escape sequences: {name}
+// and {/name} for each token of the theme, plus {/fg} to reset the
+// foreground and {text} or {reset} to reset everything. Unknown tokens are
+// left as they are.
+func Expand(text string) string {
+	if rawTokens {
+		return text
+	}
+	return tokenRe.ReplaceAllStringFunc(text, func(tok string) string {
+		name := tok[1 : len(tok)-1]
+		fixed := ""
+		switch name {
+		case "text", "reset":
+			fixed = ansiReset
+		case "/fg":
+			fixed = ansiResetFG
+		default:
+			closing := strings.HasPrefix(name, "/")
+			name = strings.TrimPrefix(name, "/")
+			if _, ok := activeTheme[name]; !ok {
+				return tok
+			}
+			if closing {
+				return tokenEnd(name)
+			}
+			return tokenStart(name)
+		}
+		if !colorsEnabled {
+			return ""
+		}
+		return fixed
+	})
+}
+
+func dim(text string) string {
+	return styled("dim", text)
+}
+
+func bold(text string) string {
+	return styled("section", text)
+}
+
+func highlight(text string) string {
+	return styled("b", text)
+}
+
+func accent(text string) string {
+	return styled("h1", text)
+}
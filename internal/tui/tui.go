@@ -3,14 +3,20 @@ package tui
 
 import (
 	"fmt"
-	"math"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/amulcse/try/internal/cellwidth"
+	"github.com/amulcse/try/internal/config"
 	"github.com/amulcse/try/internal/fuzzy"
+	"github.com/amulcse/try/internal/grep"
+	"github.com/amulcse/try/internal/index"
+	"github.com/amulcse/try/internal/watch"
 	"golang.org/x/term"
 )
 
@@ -29,59 +35,19 @@ const (
 	ansiBold          = "\x1b[1m"
 	ansiReverse       = "\x1b[7m"
 	ansiReverseOff    = "\x1b[27m"
-
-	// Colors
-	colorMuted      = "\x1b[38;5;245m"
-	colorHighlight  = "\x1b[1m\x1b[33m"       // Bold + yellow (separate sequences)
-	colorAccent     = "\x1b[1m\x1b[38;5;214m" // Bold + 256-color orange (separate)
-	colorSelectedBG = "\x1b[48;5;238m"
-	colorDangerBG   = "\x1b[48;5;52m"
 )
 
-var colorsEnabled = true
-
-// DisableColors disables ANSI color output
-func DisableColors() {
-	colorsEnabled = false
-}
-
-// EnableColors enables ANSI color output
-func EnableColors() {
-	colorsEnabled = true
-}
-
-func ansiWrap(text, prefix, suffix string) string {
-	if text == "" || !colorsEnabled {
-		return text
-	}
-	return prefix + text + suffix
-}
-
-func dim(text string) string {
-	return ansiWrap(text, colorMuted, ansiResetFG)
-}
-
-func bold(text string) string {
-	return ansiWrap(text, ansiBold, ansiReset)
-}
-
-func highlight(text string) string {
-	return ansiWrap(text, colorHighlight, ansiResetFG+"\x1b[22m")
-}
-
-func accent(text string) string {
-	return ansiWrap(text, colorAccent, ansiResetFG+"\x1b[22m")
-}
-
 // Item represents a directory entry
 type Item struct {
 	Text      string
 	Basename  string
 	Path      string
 	IsNew     bool
-	Ctime     time.Time
+	Ctime     time.Time // creation date, from the name's date prefix when present
 	Mtime     time.Time
 	BaseScore float64
+	Tags      []string
+	Size      int64 // bytes, filled in only while sorting by size
 }
 
 // Entry wraps an Item with match data
@@ -93,68 +59,121 @@ type Entry struct {
 
 // SelectionResult is the result of the TUI selection
 type SelectionResult struct {
-	Type     string       // "cd", "mkdir", "delete", "rename"
-	Path     string       // for cd/mkdir
-	Paths    []DeletePath // for delete
-	BasePath string       // for delete/rename
+	Type     string       // "cd", "mkdir", "delete", "rename", "edit", "tmux", "print", "archive", "tag", "move", "export"
+	Path     string       // for cd/mkdir/edit/tmux/print
+	Paths    []MarkedPath // for delete and the other bulk actions
+	BasePath string       // for delete/rename and the other bulk actions
 	OldName  string       // for rename
 	NewName  string       // for rename
+	Target   string       // for move: the destination root
+	Tags     string       // for tag: tags to add, "-tag" to remove
 }
 
-// DeletePath represents a path marked for deletion
-type DeletePath struct {
+// MarkedPath represents a path marked for a bulk action
+type MarkedPath struct {
 	Path     string
-	Basename string
+	Basename string // relative to the base path (includes YYYY/MM when nested)
 }
 
 // Selector is the interactive TUI selector
 type Selector struct {
-	searchTerm      string
-	cursorPos       int
-	inputCursorPos  int
-	scrollOffset    int
-	inputBuffer     string
-	selected        *SelectionResult
-	allTries        []Item
-	basePath        string
-	deleteStatus    string
-	deleteMode      bool
-	markedForDelete []string
-	testRenderOnce  bool
-	testNoCls       bool
-	testKeys        []string
-	testHadKeys     bool
-	testConfirm     string
-	NeedsRedraw     bool
-	matcher         *fuzzy.Matcher
-	io              *os.File
-	oldState        *term.State
-	width           int
-	height          int
+	searchTerm     string
+	cursorPos      int
+	inputCursorPos int
+	scrollOffset   int
+	inputBuffer    string
+	selected       *SelectionResult
+	allTries       []Item
+	basePath       string
+	status         string // shown in the footer until statusUntil
+	statusUntil    time.Time
+	deleteMode     bool
+	markMode       bool
+	actionMenu     bool
+	sortMode       string
+	caseMode       string
+	contentSearch  bool                // match inside tries instead of names
+	matches        []fuzzy.Match       // results for matchesFor
+	snippets       map[string]grep.Hit // content search: first hit by path
+	matchesFor     string
+	pending        *pendingMatch
+	wake           chan struct{} // signalled when a background match finishes
+	index          *index.Index
+	reconciling    chan *index.Index // the live scan behind a cached index
+	watcher        watch.Watcher
+	watched        []string
+	triesChanged   bool   // the watcher saw a change not scanned yet
+	follow         string // path the cursor stays on while the list is rebuilt
+	sizes          map[string]int64
+	sizing         *sizing           // sizes still being measured
+	inline         bool              // draw below the prompt instead of on the alternate screen
+	inlineRows     int               // rows of the inline region, fixed once reserved
+	regionTop      int               // terminal row the inline region starts on, 0 if unknown
+	branches       map[string]string // git branch of each try, read for {git}
+	format         *lineFormat
+	marked         []string
+	testRenderOnce bool
+	testNoCls      bool
+	testKeys       []string
+	testHadKeys    bool
+	testConfirm    string
+	NeedsRedraw    bool // a dialog closed; clear the screen for the next frame
+	input          chan []byte
+	stopInputCh    chan struct{}
+	inputDone      chan struct{} // closed when the input reader exits
+	inputWake      *os.File      // closing it wakes the input reader
+	inputClosed    bool
+	keys           keyDecoder
+	resized        chan struct{}
+	mouse          bool  // mouse reporting is on
+	listTop        int   // terminal row of the first list row
+	listRows       []int // entry index shown on each list row, -1 for none
+	lastClick      int   // entry clicked last, for double clicks
+	lastClickAt    time.Time
+	matcher        *fuzzy.Matcher
+	config         *config.Config
+	keymap         *Keymap
+	keymapErrs     []error // configured bindings that were skipped
+	mode           string  // keymap mode: insert, or normal in the vim preset
+	io             *os.File
+	oldState       *term.State
+	width          int
+	height         int
 }
 
 // NewSelector creates a new Selector
-func NewSelector(searchTerm, basePath, andType string, andExit bool, andKeys []string, andConfirm string) *Selector {
+func NewSelector(searchTerm, basePath, andType string, andExit bool, andKeys []string, andConfirm string, cfg *config.Config) *Selector {
 	initialInput := searchTerm
 	if andType != "" {
 		initialInput = andType
 	}
-	initialInput = strings.ReplaceAll(initialInput, " ", "-")
 
+	keymap, keymapErrs := NewKeymap(cfg)
 	s := &Selector{
-		searchTerm:      strings.ReplaceAll(searchTerm, " ", "-"),
-		inputBuffer:     initialInput,
-		inputCursorPos:  len(initialInput),
-		basePath:        basePath,
-		markedForDelete: []string{},
-		testRenderOnce:  andExit,
-		testNoCls:       andExit || (andKeys != nil && len(andKeys) > 0),
-		testKeys:        andKeys,
-		testHadKeys:     andKeys != nil && len(andKeys) > 0,
-		testConfirm:     andConfirm,
-		io:              os.Stderr,
-		width:           80,
-		height:          24,
+		keymap:         keymap,
+		keymapErrs:     keymapErrs,
+		searchTerm:     searchTerm,
+		inputBuffer:    initialInput,
+		inputCursorPos: len(initialInput),
+		basePath:       basePath,
+		marked:         []string{},
+		testRenderOnce: andExit,
+		testNoCls:      andExit || (andKeys != nil && len(andKeys) > 0),
+		testKeys:       andKeys,
+		testHadKeys:    andKeys != nil && len(andKeys) > 0,
+		testConfirm:    andConfirm,
+		config:         cfg,
+		mode:           modeInsert,
+		sortMode:       cfg.Sort,
+		caseMode:       cfg.Match.Case,
+		wake:           make(chan struct{}, 1),
+		resized:        make(chan struct{}, 1),
+		lastClick:      -1,
+		inline:         cfg.Height != "",
+		format:         parseFormat(cfg.Display.Format),
+		io:             os.Stderr,
+		width:          80,
+		height:         24,
 	}
 
 	// Ensure base path exists
@@ -165,6 +184,11 @@ func NewSelector(searchTerm, basePath, andType string, andExit bool, andKeys []s
 	return s
 }
 
+// KeymapErrors returns the configured key bindings that were skipped
+func (s *Selector) KeymapErrors() []error {
+	return s.keymapErrs
+}
+
 // Run starts the TUI and returns the selection result
 func (s *Selector) Run() *SelectionResult {
 	s.setupTerminal()
@@ -191,8 +215,14 @@ func (s *Selector) Run() *SelectionResult {
 		if err == nil {
 			s.oldState = oldState
 		}
+		if s.inline && !s.testNoCls {
+			s.locateRegion()
+		}
 	}
 
+	defer s.stopWatching()
+	defer s.stopSizing()
+	defer s.stopInput()
 	s.mainLoop()
 	return s.selected
 }
@@ -201,16 +231,27 @@ func (s *Selector) setupTerminal() {
 	s.refreshSize()
 
 	if !s.testNoCls {
-		fmt.Fprint(s.io, ansiAltScreenOn)
-		fmt.Fprint(s.io, ansiClearScreen)
-		fmt.Fprint(s.io, ansiHome)
+		if s.inline {
+			s.reserveRegion()
+		} else {
+			fmt.Fprint(s.io, ansiAltScreenOn)
+		}
+		fmt.Fprint(s.io, ansiPasteOn)
+		if s.config.Mouse {
+			fmt.Fprint(s.io, ansiMouseOn)
+			s.mouse = true
+		}
+		fmt.Fprint(s.io, s.clearFrame())
 		fmt.Fprint(s.io, ansiCursorBlink)
 		s.io.Sync() // Flush to ensure alternate screen is active
 	}
 
 	// Handle window resize (Unix only, no-op on Windows)
 	SetupResizeHandler(func() {
-		s.NeedsRedraw = true
+		select {
+		case s.resized <- struct{}{}:
+		default:
+		}
 	})
 }
 
@@ -220,9 +261,19 @@ func (s *Selector) restoreTerminal() {
 	}
 
 	if !s.testNoCls {
+		if s.inline {
+			fmt.Fprint(s.io, s.clearFrame())
+		}
 		fmt.Fprint(s.io, ansiReset)
 		fmt.Fprint(s.io, ansiCursorDefault)
-		fmt.Fprint(s.io, ansiAltScreenOff)
+		fmt.Fprint(s.io, ansiPasteOff)
+		if s.mouse {
+			fmt.Fprint(s.io, ansiMouseOff)
+			s.mouse = false
+		}
+		if !s.inline {
+			fmt.Fprint(s.io, ansiAltScreenOff)
+		}
 		s.io.Sync() // Flush to ensure screen is restored before any output
 	}
 }
@@ -255,95 +306,53 @@ func (s *Selector) refreshSize() {
 	if s.height <= 0 {
 		s.height = 24
 	}
-}
-
-func (s *Selector) loadAllTries() {
-	if s.allTries != nil {
-		return
-	}
-
-	entries, err := os.ReadDir(s.basePath)
-	if err != nil {
-		s.allTries = []Item{}
-		return
-	}
-
-	now := time.Now()
-	s.allTries = make([]Item, 0, len(entries))
 
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-		if !entry.IsDir() {
-			continue
-		}
-
-		path := filepath.Join(s.basePath, name)
-		info, err := entry.Info()
-		if err != nil {
-			continue
+	// Inline mode draws in a region whose rows are fixed once reserved
+	if s.inline {
+		if s.inlineRows == 0 {
+			s.inlineRows = s.regionRows(s.height)
 		}
-
-		mtime := info.ModTime()
-		hoursSinceAccess := now.Sub(mtime).Hours()
-		baseScore := 3.0 / math.Sqrt(hoursSinceAccess+1)
-
-		// Date prefix bonus
-		if matched, _ := regexp.MatchString(`^\d{4}-\d{2}-\d{2}-`, name); matched {
-			baseScore += 2.0
-		}
-
-		s.allTries = append(s.allTries, Item{
-			Text:      name,
-			Basename:  name,
-			Path:      path,
-			IsNew:     false,
-			Mtime:     mtime,
-			BaseScore: baseScore,
-		})
+		s.height = min(s.height, s.inlineRows)
 	}
 }
 
 func (s *Selector) getTries() []Entry {
 	s.loadAllTries()
 	if s.matcher == nil {
+		s.resetMatches()
 		items := make([]fuzzy.Item, len(s.allTries))
 		for i, t := range s.allTries {
 			items[i] = fuzzy.Item{
 				Text:      t.Text,
 				Path:      t.Path,
 				BaseScore: t.BaseScore,
+				Mtime:     t.Mtime,
 			}
 		}
-		s.matcher = fuzzy.New(items)
+		s.matcher = fuzzy.New(items, fuzzy.Options{Algorithm: s.config.Match.Algorithm, Case: s.caseMode})
 	}
 
-	matches := s.matcher.Match(s.inputBuffer)
+	matches := s.currentMatches()
 	results := make([]Entry, 0, len(matches))
 	for _, m := range matches {
-		// Find the original item
-		var item Item
-		for _, t := range s.allTries {
-			if t.Path == m.Entry.Path {
-				item = t
-				break
-			}
-		}
 		results = append(results, Entry{
-			Item:               item,
+			Item:               s.allTries[m.Index],
 			Score:              m.Score,
 			HighlightPositions: m.Positions,
 		})
 	}
+	s.sortEntries(results)
 	return results
 }
 
 func (s *Selector) mainLoop() {
 	for {
+		s.pollReconcile()
+		s.pollWatch()
+		s.pollSizes()
 		tries := s.getTries()
-		showCreateNew := s.inputBuffer != ""
+		s.followCursor(tries)
+		showCreateNew := s.inputBuffer != "" && !s.contentSearch
 		totalItems := len(tries)
 		if showCreateNew {
 			totalItems++
@@ -364,17 +373,41 @@ func (s *Selector) mainLoop() {
 		s.render(tries)
 
 		key := s.readKey()
+		if ev, ok := parseMouse(key); ok {
+			key = ""
+			if s.config.Mouse {
+				key = s.handleMouse(ev)
+			}
+		}
 		if key == "" {
-			continue // resize or timeout
+			continue // resize, background work or mouse
 		}
 
-		switch key {
-		case "\r", "\n": // Enter
-			if s.deleteMode && len(s.markedForDelete) > 0 {
+		// The bulk action menu takes single-key choices
+		if s.actionMenu {
+			s.handleActionMenu(key, tries)
+			if s.selected != nil {
+				return
+			}
+			continue
+		}
+
+		action := s.keymap.action(s.mode, key)
+		switch action {
+		case ActionCd, ActionEdit, ActionTmux, ActionPrint:
+			if !s.deleteMode && !s.markMode && s.cursorPos < len(tries) {
+				s.handleAction(tries[s.cursorPos], action)
+				return
+			}
+
+		case actSelect:
+			if s.deleteMode && len(s.marked) > 0 {
 				s.confirmBatchDelete(tries)
 				if s.selected != nil {
 					return
 				}
+			} else if s.markMode && len(s.marked) > 0 {
+				s.actionMenu = true
 			} else if s.cursorPos < len(tries) {
 				s.handleSelection(tries[s.cursorPos])
 				if s.selected != nil {
@@ -387,82 +420,65 @@ func (s *Selector) mainLoop() {
 				}
 			}
 
-		case "\x1b[A", "\x10": // Up arrow or Ctrl-P
+		case actMoveUp:
 			if s.cursorPos > 0 {
 				s.cursorPos--
 			}
 
-		case "\x1b[B", "\x0e": // Down arrow or Ctrl-N
+		case actMoveDown:
 			if s.cursorPos < totalItems-1 {
 				s.cursorPos++
 			}
 
-		case "\x1b[C", "\x1b[D": // Left/Right arrows - ignore
-
-		case "\x7f", "\x08": // Backspace (DEL) or Ctrl-H
-			if s.inputCursorPos > 0 {
-				s.inputBuffer = s.inputBuffer[:s.inputCursorPos-1] + s.inputBuffer[s.inputCursorPos:]
-				s.inputCursorPos--
-			}
+		case actMoveFirst:
 			s.cursorPos = 0
 
-		case "\x01": // Ctrl-A
-			s.inputCursorPos = 0
-
-		case "\x05": // Ctrl-E
-			s.inputCursorPos = len(s.inputBuffer)
-
-		case "\x02": // Ctrl-B
-			if s.inputCursorPos > 0 {
-				s.inputCursorPos--
-			}
-
-		case "\x06": // Ctrl-F
-			if s.inputCursorPos < len(s.inputBuffer) {
-				s.inputCursorPos++
-			}
-
-		case "\x0b": // Ctrl-K
-			s.inputBuffer = s.inputBuffer[:s.inputCursorPos]
+		case actMoveLast:
+			s.cursorPos = totalItems - 1
 
-		case "\x17": // Ctrl-W
-			if s.inputCursorPos > 0 {
-				pos := s.inputCursorPos - 1
-				// Skip non-alphanumeric
-				for pos >= 0 && !isWordChar(rune(s.inputBuffer[pos])) {
-					pos--
-				}
-				// Skip alphanumeric
-				for pos >= 0 && isWordChar(rune(s.inputBuffer[pos])) {
-					pos--
+		case actDelete: // toggle delete mark
+			if s.cursorPos < len(tries) {
+				s.toggleMark(tries[s.cursorPos].Item.Path)
+				if len(s.marked) > 0 {
+					s.deleteMode, s.markMode = true, false
 				}
-				newPos := pos + 1
-				s.inputBuffer = s.inputBuffer[:newPos] + s.inputBuffer[s.inputCursorPos:]
-				s.inputCursorPos = newPos
 			}
 
-		case "\x04": // Ctrl-D - toggle delete mark
+		case actToggleMark: // toggle mark and move down
 			if s.cursorPos < len(tries) {
-				path := tries[s.cursorPos].Item.Path
-				idx := indexOf(s.markedForDelete, path)
-				if idx >= 0 {
-					s.markedForDelete = append(s.markedForDelete[:idx], s.markedForDelete[idx+1:]...)
-				} else {
-					s.markedForDelete = append(s.markedForDelete, path)
-					s.deleteMode = true
+				s.toggleMark(tries[s.cursorPos].Item.Path)
+				if len(s.marked) > 0 {
+					s.deleteMode, s.markMode = false, true
 				}
-				if len(s.markedForDelete) == 0 {
-					s.deleteMode = false
+				if s.cursorPos < len(tries)-1 {
+					s.cursorPos++
 				}
 			}
 
-		case "\x14": // Ctrl-T - create new
+		case actCreate:
 			s.handleCreateNew()
 			if s.selected != nil {
 				return
 			}
 
-		case "\x12": // Ctrl-R - rename
+		case actCycleSort:
+			s.sortMode = nextSortMode(s.sortMode)
+			s.cursorPos = 0
+			s.scrollOffset = 0
+
+		case actCycleCase:
+			s.caseMode = nextCaseMode(s.caseMode)
+			s.resetMatches()
+			s.cursorPos = 0
+			s.scrollOffset = 0
+
+		case actToggleGrep:
+			s.contentSearch = !s.contentSearch
+			s.resetMatches()
+			s.cursorPos = 0
+			s.scrollOffset = 0
+
+		case actRename:
 			if s.cursorPos < len(tries) {
 				s.runRenameDialog(tries[s.cursorPos])
 				if s.selected != nil {
@@ -470,20 +486,35 @@ func (s *Selector) mainLoop() {
 				}
 			}
 
-		case "\x03", "\x1b": // Ctrl-C or ESC
-			if s.deleteMode {
-				s.markedForDelete = nil
-				s.deleteMode = false
+		case actClearInput:
+			s.inputBuffer, s.inputCursorPos = "", 0
+			s.cursorPos = 0
+
+		case actNormalMode:
+			s.mode = modeNormal
+
+		case actInsertMode:
+			s.mode = modeInsert
+
+		case actHelp:
+			s.showHelp()
+
+		case actCancel:
+			if s.deleteMode || s.markMode {
+				s.clearMarks()
 			} else {
 				s.selected = nil
 				return
 			}
 
 		default:
-			// Only accept printable characters
-			if len(key) == 1 && isPrintable(key[0]) {
-				s.inputBuffer = s.inputBuffer[:s.inputCursorPos] + key + s.inputBuffer[s.inputCursorPos:]
-				s.inputCursorPos++
+			// Line editing and typing, which normal mode leaves alone
+			if s.mode != modeInsert {
+				continue
+			}
+			var edited bool
+			s.inputBuffer, s.inputCursorPos, edited = s.editLine(s.inputBuffer, s.inputCursorPos, key, isPrintable)
+			if edited {
 				s.cursorPos = 0
 			}
 		}
@@ -501,53 +532,93 @@ func (s *Selector) readKey() string {
 		return "\x1b"
 	}
 
-	// Set read deadline for resize checking
-	buf := make([]byte, 6)
-	n, err := os.Stdin.Read(buf)
-	if err != nil {
-		if s.NeedsRedraw {
-			s.NeedsRedraw = false
-			s.refreshSize()
-			return ""
-		}
+	// A dialog that closed asks for a clean frame
+	if s.NeedsRedraw {
+		s.NeedsRedraw = false
+		s.redraw()
 		return ""
 	}
 
-	if s.NeedsRedraw {
-		s.NeedsRedraw = false
-		s.refreshSize()
-		if !s.testNoCls {
-			fmt.Fprint(s.io, ansiClearScreen+ansiHome)
+	// Once stdin is closed every read cancels
+	if s.inputClosed {
+		return "\x03"
+	}
+	if s.input == nil {
+		s.startInput()
+	}
+
+	// Wait for a whole key, a resize, or background work to finish: a
+	// match, a scan, or a change the watcher saw. An empty key redraws.
+	for {
+		if key, ok := s.keys.next(false); ok {
+			return key
+		}
+		var escTimeout <-chan time.Time
+		if s.keys.incomplete() {
+			escTimeout = time.After(escWait)
+		}
+		select {
+		case data, ok := <-s.input:
+			if !ok {
+				s.inputClosed = true
+				return "\x03"
+			}
+			s.keys.feed(data)
+		case <-escTimeout:
+			if key, ok := s.keys.next(true); ok {
+				return key
+			}
+		case <-s.resized:
+			s.redraw()
+			return ""
+		case <-s.wake:
+			return ""
+		case <-s.statusExpiry():
+			return ""
+		case <-s.watchChanges():
+			s.triesChanged = true
+			return ""
 		}
-		return ""
 	}
+}
 
-	return string(buf[:n])
+// redraw picks up the terminal size and clears the screen for a full frame
+func (s *Selector) redraw() {
+	s.refreshSize()
+	if !s.testNoCls {
+		fmt.Fprint(s.io, s.clearFrame())
+	}
 }
 
 func (s *Selector) render(tries []Entry) {
 	s.refreshSize()
 	var out strings.Builder
 
-	out.WriteString(ansiHome)
+	out.WriteString(s.home())
 
 	// Header
 	headerLines := []string{}
-	headerLines = append(headerLines, s.renderHeaderLine("🏠", accent(" Try Directory Selection")))
+	headerLines = append(headerLines, s.renderHeaderLine(s.icon("🏠", ""), accent(" Try Directory Selection"), dim(s.headerHints())))
 	headerLines = append(headerLines, dim(strings.Repeat("─", s.width-1)))
 	headerLines = append(headerLines, s.renderSearchLine())
 	headerLines = append(headerLines, dim(strings.Repeat("─", s.width-1)))
 
 	// Footer
 	footerLines := []string{}
+	if s.contentSearch {
+		footerLines = append(footerLines, s.renderSnippetLine(tries))
+	}
 	footerLines = append(footerLines, dim(strings.Repeat("─", s.width-1)))
-	if s.deleteStatus != "" {
-		footerLines = append(footerLines, bold(s.deleteStatus))
-		s.deleteStatus = ""
+	if s.statusShown() {
+		footerLines = append(footerLines, bold(s.status))
 	} else if s.deleteMode {
 		footerLines = append(footerLines, s.renderDeleteModeFooter())
+	} else if s.actionMenu {
+		footerLines = append(footerLines, s.renderActionMenuFooter())
+	} else if s.markMode {
+		footerLines = append(footerLines, s.renderMarkModeFooter())
 	} else {
-		footerLines = append(footerLines, s.centerText(dim("↑/↓: Navigate  Enter: Select  ^R: Rename  ^D: Delete  Esc: Cancel")))
+		footerLines = append(footerLines, s.centerText(dim(s.footerHints())))
 	}
 
 	// Calculate body space
@@ -556,7 +627,7 @@ func (s *Selector) render(tries []Entry) {
 		maxVisible = 3
 	}
 
-	showCreateNew := s.inputBuffer != ""
+	showCreateNew := s.inputBuffer != "" && !s.contentSearch
 	totalItems := len(tries)
 	if showCreateNew {
 		totalItems++
@@ -581,17 +652,21 @@ func (s *Selector) render(tries []Entry) {
 		out.WriteString("\n")
 	}
 
-	// Write body
+	// Write body, remembering which entry each row shows for the mouse
 	bodyLinesRendered := 0
+	s.listTop = len(headerLines) + 1
+	s.listRows = s.listRows[:0]
 	for idx := s.scrollOffset; idx < visibleEnd; idx++ {
 		// Empty line before "Create new" if there are existing entries
 		if idx == len(tries) && len(tries) > 0 && idx >= s.scrollOffset {
 			out.WriteString("\r" + ansiClearEOL + "\n")
+			s.listRows = append(s.listRows, -1)
 			bodyLinesRendered++
 			if bodyLinesRendered >= maxVisible {
 				break
 			}
 		}
+		s.listRows = append(s.listRows, idx)
 
 		if idx < len(tries) {
 			out.WriteString("\r" + ansiClearEOL)
@@ -622,21 +697,33 @@ func (s *Selector) render(tries []Entry) {
 
 	// Position cursor at search input
 	searchLineRow := 3 // Header line 3 (1-indexed)
-	searchPrefix := "Search: "
-	cursorCol := len(searchPrefix) + s.inputCursorPos + 1
-	out.WriteString(fmt.Sprintf("\x1b[%d;%dH", searchLineRow, cursorCol))
+	cursorCol := len(s.searchPrompt()) + cellwidth.String(s.inputBuffer[:s.inputCursorPos]) + 1
+	out.WriteString(s.cursorTo(searchLineRow, cursorCol))
 	out.WriteString(ansiShow)
 	out.WriteString(ansiReset)
 
 	s.io.WriteString(out.String())
 }
 
-func (s *Selector) renderHeaderLine(emoji, text string) string {
-	return emoji + text
+// renderHeaderLine renders the title with status right-aligned
+func (s *Selector) renderHeaderLine(emoji, text, status string) string {
+	left := emoji + text
+	gap := s.width - 1 - visibleLen(left) - visibleLen(status)
+	if gap < 1 {
+		return left
+	}
+	return left + strings.Repeat(" ", gap) + status
+}
+
+func (s *Selector) searchPrompt() string {
+	if s.contentSearch {
+		return "Grep: "
+	}
+	return "Search: "
 }
 
 func (s *Selector) renderSearchLine() string {
-	prefix := dim("Search: ")
+	prefix := dim(s.searchPrompt())
 	input := s.renderInput(s.inputBuffer, s.inputCursorPos)
 	return prefix + input
 }
@@ -648,12 +735,11 @@ func (s *Selector) renderInput(text string, cursor int) string {
 
 	before := text[:cursor]
 	cursorChar := " "
-	if cursor < len(text) {
-		cursorChar = string(text[cursor])
-	}
 	after := ""
 	if cursor < len(text) {
-		after = text[cursor+1:]
+		next := nextRune(text, cursor)
+		cursorChar = text[cursor:next]
+		after = text[next:]
 	}
 
 	var out strings.Builder
@@ -670,14 +756,16 @@ func (s *Selector) renderInput(text string, cursor int) string {
 }
 
 func (s *Selector) renderEntryLine(entry Entry, isSelected bool) string {
-	isMarked := indexOf(s.markedForDelete, entry.Item.Path) >= 0
+	isMarked := indexOf(s.marked, entry.Item.Path) >= 0
 	var out strings.Builder
 
 	// Background
-	if isMarked && colorsEnabled {
-		out.WriteString(colorDangerBG)
-	} else if isSelected && colorsEnabled {
-		out.WriteString(colorSelectedBG)
+	if isMarked && s.deleteMode {
+		out.WriteString(tokenStart("strike"))
+	} else if isMarked {
+		out.WriteString(tokenStart("marked"))
+	} else if isSelected {
+		out.WriteString(tokenStart("selected"))
 	}
 
 	// Arrow
@@ -687,43 +775,36 @@ func (s *Selector) renderEntryLine(entry Entry, isSelected bool) string {
 		out.WriteString("  ")
 	}
 
-	// Emoji
-	if isMarked {
-		out.WriteString("🗑️ ")
-	} else {
-		out.WriteString("📁 ")
-	}
-
-	// Name with highlights
+	// Fields, with the name truncated only if it exceeds the line width
+	// (not just to make room for the right-aligned part)
 	plainName, renderedName := s.formattedEntryName(entry)
-
-	// Metadata (right-aligned)
-	meta := fmt.Sprintf("%s, %.1f", FormatRelativeTime(entry.Item.Mtime), entry.Score)
-
-	// Calculate available width (max content = width - 1 to avoid wrapping)
-	maxContent := s.width - 1
-	prefixWidth := 5 // "→ " or "  " + emoji + space = ~5 visible chars
-	metaWidth := visibleLen(meta)
-
-	// Truncate name only if it exceeds line width (not just to make room for metadata)
-	maxNameWidth := maxContent - prefixWidth - 1
+	value := func(field string) string {
+		if field == fieldName {
+			return renderedName
+		}
+		return s.fieldValue(entry, field)
+	}
+	maxContent := s.width - 1 // avoid wrapping
+	arrowWidth := 2
+	maxNameWidth := maxContent - arrowWidth - visibleLen(renderSegments(s.format.left, func(field string) string {
+		if field == fieldName {
+			return ""
+		}
+		return value(field)
+	})) - 1
 	if visibleLen(plainName) > maxNameWidth && maxNameWidth > 2 {
 		renderedName = truncateWithAnsi(renderedName, maxNameWidth-1) + "…"
 	}
+	left := renderSegments(s.format.left, value)
+	right := renderSegments(s.format.right, value)
+	out.WriteString(left)
 
-	out.WriteString(renderedName)
-
-	// Calculate positions for right-aligned metadata
-	nameWidth := visibleLen(renderedName)
-	leftContentWidth := prefixWidth + nameWidth
-	rightCol := maxContent - metaWidth
-
-	// Fill gap with spaces to position metadata at right edge
-	gap := rightCol - leftContentWidth
+	// Fill the gap with spaces to put the right part at the right edge
+	gap := maxContent - visibleLen(right) - arrowWidth - visibleLen(left)
 	if gap > 0 {
 		out.WriteString(strings.Repeat(" ", gap))
 	}
-	out.WriteString(dim(meta))
+	out.WriteString(right)
 
 	return out.String()
 }
@@ -732,6 +813,14 @@ func (s *Selector) formattedEntryName(entry Entry) (plain, rendered string) {
 	basename := entry.Item.Basename
 	positions := entry.HighlightPositions
 
+	// Nested layout: the year/month directories precede the basename
+	var out strings.Builder
+	dirPart := strings.TrimSuffix(entry.Item.Text, basename)
+	offset := len([]rune(dirPart))
+	if dirPart != "" {
+		out.WriteString(dimWithPositions(dirPart, positions, 0))
+	}
+
 	// Check for date prefix
 	dateRe := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
 	if m := dateRe.FindStringSubmatch(basename); m != nil {
@@ -739,30 +828,52 @@ func (s *Selector) formattedEntryName(entry Entry) (plain, rendered string) {
 		namePart := m[2]
 		dateLen := len(datePart) + 1 // +1 for hyphen
 
-		var rendered strings.Builder
-		rendered.WriteString(dim(datePart))
+		out.WriteString(dim(datePart))
 		// Hyphen highlight check
-		if containsInt(positions, 10) {
-			rendered.WriteString(highlight("-"))
+		if containsInt(positions, offset+dateLen-1) {
+			out.WriteString(highlight("-"))
 		} else {
-			rendered.WriteString(dim("-"))
+			out.WriteString(dim("-"))
 		}
-		rendered.WriteString(highlightWithPositions(namePart, positions, dateLen))
+		out.WriteString(highlightWithPositions(namePart, positions, offset+dateLen))
 
-		return basename, rendered.String()
+		return dirPart + basename, out.String()
 	}
 
-	return basename, highlightWithPositions(basename, positions, 0)
+	out.WriteString(highlightWithPositions(basename, positions, offset))
+	return dirPart + basename, out.String()
 }
 
+// highlightWithPositions renders text with the characters at the matched
+// rune positions highlighted. positions index runes of the whole entry
+// text, of which text starts at rune offset; a mark or emoji sequence is
+// highlighted as a whole when any of its runes matched.
 func highlightWithPositions(text string, positions []int, offset int) string {
+	return renderWithPositions(text, positions, offset, func(s string) string { return s })
+}
+
+// dimWithPositions renders text muted, keeping matched characters highlighted
+func dimWithPositions(text string, positions []int, offset int) string {
+	return renderWithPositions(text, positions, offset, dim)
+}
+
+func renderWithPositions(text string, positions []int, offset int, plain func(string) string) string {
 	var result strings.Builder
-	for i, ch := range text {
-		if containsInt(positions, i+offset) {
-			result.WriteString(highlight(string(ch)))
+	i := offset
+	for _, c := range cellwidth.Clusters(text) {
+		matched := false
+		for j := i; j < i+c.Runes; j++ {
+			if containsInt(positions, j) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			result.WriteString(highlight(c.Text))
 		} else {
-			result.WriteString(string(ch))
+			result.WriteString(plain(c.Text))
 		}
+		i += c.Runes
 	}
 	return result.String()
 }
@@ -770,8 +881,8 @@ func highlightWithPositions(text string, positions []int, offset int) string {
 func (s *Selector) renderCreateLine(isSelected bool) string {
 	var out strings.Builder
 
-	if isSelected && colorsEnabled {
-		out.WriteString(colorSelectedBG)
+	if isSelected {
+		out.WriteString(tokenStart("selected"))
 	}
 
 	if isSelected {
@@ -780,11 +891,17 @@ func (s *Selector) renderCreateLine(isSelected bool) string {
 		out.WriteString("  ")
 	}
 
-	datePrefix := time.Now().Format("2006-01-02")
-	if s.inputBuffer == "" {
-		out.WriteString(fmt.Sprintf("📂 Create new: %s-", datePrefix))
+	// Preview the name exactly as it will be created
+	now := time.Now()
+	datePrefix := now.Format("2006-01-02")
+	if s.config.Nested() {
+		datePrefix = config.NestedDir(now) + "/" + datePrefix
+	}
+	name := s.config.Slugify(s.inputBuffer)
+	if name == "" {
+		out.WriteString(fmt.Sprintf("%s Create new: %s-", s.icon("📂", "+"), datePrefix))
 	} else {
-		out.WriteString(fmt.Sprintf("📂 Create new: %s-%s", datePrefix, s.inputBuffer))
+		out.WriteString(fmt.Sprintf("%s Create new: %s-%s", s.icon("📂", "+"), datePrefix, name))
 	}
 
 	return out.String()
@@ -792,11 +909,9 @@ func (s *Selector) renderCreateLine(isSelected bool) string {
 
 func (s *Selector) renderDeleteModeFooter() string {
 	var out strings.Builder
-	if colorsEnabled {
-		out.WriteString(colorDangerBG)
-	}
+	out.WriteString(tokenStart("strike"))
 	out.WriteString(bold(" DELETE MODE "))
-	out.WriteString(fmt.Sprintf(" %d marked  |  Ctrl-D: Toggle  Enter: Confirm  Esc: Cancel", len(s.markedForDelete)))
+	out.WriteString(fmt.Sprintf(" %d marked  |  %s", len(s.marked), s.markHints(actDelete, "Confirm")))
 	return out.String()
 }
 
@@ -823,12 +938,21 @@ func (s *Selector) handleSelection(entry Entry) {
 	}
 }
 
+// handleAction selects entry for one of the configured alternate actions
+func (s *Selector) handleAction(entry Entry, action string) {
+	s.selected = &SelectionResult{
+		Type: action,
+		Path: entry.Item.Path,
+	}
+}
+
 func (s *Selector) handleCreateNew() {
-	datePrefix := time.Now().Format("2006-01-02")
+	now := time.Now()
+	datePrefix := now.Format("2006-01-02")
 
-	if s.inputBuffer != "" {
-		finalName := fmt.Sprintf("%s-%s", datePrefix, strings.ReplaceAll(s.inputBuffer, " ", "-"))
-		fullPath := filepath.Join(s.basePath, finalName)
+	if name := s.config.Slugify(s.inputBuffer); name != "" {
+		finalName := fmt.Sprintf("%s-%s", datePrefix, name)
+		fullPath := filepath.Join(s.config.ParentDir(s.basePath, now), finalName)
 		s.selected = &SelectionResult{
 			Type: "mkdir",
 			Path: fullPath,
@@ -841,74 +965,83 @@ func (s *Selector) handleCreateNew() {
 }
 
 func (s *Selector) confirmBatchDelete(tries []Entry) {
-	// Find marked items
-	markedItems := []Entry{}
-	for _, t := range tries {
-		if indexOf(s.markedForDelete, t.Item.Path) >= 0 {
-			markedItems = append(markedItems, t)
-		}
-	}
+	markedItems := s.markedEntries(tries)
 	if len(markedItems) == 0 {
 		return
 	}
 
-	// In test mode, use provided confirmation
-	var confirmationBuffer string
-	if s.testKeys != nil && len(s.testKeys) > 0 {
-		// Read from test keys
-		for len(s.testKeys) > 0 {
-			ch := s.testKeys[0]
-			s.testKeys = s.testKeys[1:]
-			if ch == "\r" || ch == "\n" {
-				break
-			}
-			confirmationBuffer += ch
-		}
-		s.processDeleteConfirmation(markedItems, confirmationBuffer)
+	// Sizes and git state fill in while the dialog is open; tests wait for
+	// them so every run shows the same dialog
+	in := s.startInspection(markedItems)
+	defer in.stop()
+	if s.testHadKeys {
+		in.wait()
+	}
+
+	// In test mode, --and-confirm answers once the keys run out
+	confirmation, ok := s.testConfirm, s.testConfirm != "" && len(s.testKeys) == 0
+	if !ok {
+		confirmation, ok = s.readDeleteConfirmation(markedItems, in, "Type YES to confirm: ")
+	}
+	if !ok || confirmation != "YES" {
+		s.cancelDelete()
 		return
-	} else if s.testConfirm != "" {
-		confirmationBuffer = s.testConfirm
-		s.processDeleteConfirmation(markedItems, confirmationBuffer)
+	}
+
+	// Unpushed commits exist nowhere else, so losing them takes a second word
+	if !s.awaitGitChecks(markedItems, in) {
+		s.cancelDelete()
 		return
 	}
+	if in.unpushedTries() > 0 {
+		confirmation, ok = s.readDeleteConfirmation(markedItems, in, "Type DELETE to lose unpushed commits: ")
+		if !ok || confirmation != "DELETE" {
+			s.cancelDelete()
+			return
+		}
+	}
+	s.deleteMarked(markedItems)
+}
 
-	// Interactive delete confirmation
-	confirmationCursor := 0
+// readDeleteConfirmation shows the delete dialog until Enter submits what
+// was typed at prompt; it reports false if the dialog was cancelled
+func (s *Selector) readDeleteConfirmation(markedItems []Entry, in *inspection, prompt string) (string, bool) {
+	buffer, cursor := "", 0
 	for {
-		s.renderDeleteDialog(markedItems, confirmationBuffer, confirmationCursor)
+		in.poll()
+		s.renderDeleteDialog(markedItems, in, prompt, buffer, cursor)
 
 		key := s.readKey()
-		switch key {
-		case "\r", "\n":
-			s.processDeleteConfirmation(markedItems, confirmationBuffer)
-			return
-		case "\x1b":
-			s.deleteStatus = "Delete cancelled"
-			s.markedForDelete = nil
-			s.deleteMode = false
-			return
-		case "\x7f", "\b":
-			if confirmationCursor > 0 {
-				confirmationBuffer = confirmationBuffer[:confirmationCursor-1] + confirmationBuffer[confirmationCursor:]
-				confirmationCursor--
-			}
-		case "\x03":
-			s.deleteStatus = "Delete cancelled"
-			s.markedForDelete = nil
-			s.deleteMode = false
-			return
+		switch s.dialogAction(key) {
+		case actSelect:
+			return buffer, true
+		case actCancel:
+			return "", false
 		default:
-			if len(key) == 1 && key[0] >= 32 {
-				confirmationBuffer = confirmationBuffer[:confirmationCursor] + key + confirmationBuffer[confirmationCursor:]
-				confirmationCursor++
-			}
+			buffer, cursor, _ = s.editLine(buffer, cursor, key, isPromptPrintable)
 		}
 	}
 }
 
-func (s *Selector) renderDeleteDialog(markedItems []Entry, confirmation string, cursor int) {
+// awaitGitChecks keeps the delete dialog up until the git checks finish, so
+// a slow repository never skips the question about unpushed commits. It
+// reports false if the dialog was cancelled meanwhile.
+func (s *Selector) awaitGitChecks(markedItems []Entry, in *inspection) bool {
+	for {
+		in.poll()
+		if in.pendingGit == 0 {
+			return true
+		}
+		s.renderDeleteDialog(markedItems, in, "Checking for unpushed commits… ", "", 0)
+		if s.dialogAction(s.readKey()) == actCancel {
+			return false
+		}
+	}
+}
+
+func (s *Selector) renderDeleteDialog(markedItems []Entry, in *inspection, prefix, confirmation string, cursor int) {
 	var out strings.Builder
-	out.WriteString(ansiHome)
+	out.WriteString(s.home())
 
 	count := len(markedItems)
 	plural := "directories"
@@ -917,38 +1050,44 @@ func (s *Selector) renderDeleteDialog(markedItems []Entry, confirmation string,
 	}
 
 	// Header
-	header := s.centerText(fmt.Sprintf("🗑️%s  Delete %d %s?", accent(""), count, plural))
+	header := s.centerText(fmt.Sprintf("%s%s  Delete %d %s?", s.icon("🗑️", ""), accent(""), count, plural))
 	out.WriteString("\r" + ansiClearEOL + header + "\n")
 	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
 
-	// List items
-	for _, item := range markedItems {
-		line := "🗑️ " + item.Item.Basename
-		if colorsEnabled {
-			out.WriteString("\r" + ansiClearEOL + colorDangerBG + line + ansiReset + "\n")
-		} else {
-			out.WriteString("\r" + ansiClearEOL + line + "\n")
+	// List items, with what deleting them would lose at the right edge
+	for i, item := range markedItems {
+		line := styled("strike", s.icon("🗑️", "x")+" "+item.Item.Basename)
+		details := s.reportDetails(in.reports[i])
+		gap := s.width - 1 - visibleLen(line) - visibleLen(details)
+		line += strings.Repeat(" ", max(gap, 2)) + details
+		out.WriteString("\r" + ansiClearEOL + s.truncateLine(line) + "\n")
+	}
+
+	// Warning once the checks found unpushed commits, then a blank line
+	warning := ""
+	if n := in.unpushedTries(); n > 0 && in.pendingGit == 0 {
+		what := "tries have"
+		if n == 1 {
+			what = "try has"
 		}
+		warning = s.centerText(s.icon("⚠️", "!") + "  " + highlight(fmt.Sprintf("%d %s unpushed commits that exist nowhere else", n, what)))
 	}
-
-	// Blank lines
-	out.WriteString("\r" + ansiClearEOL + "\n")
+	out.WriteString("\r" + ansiClearEOL + warning + "\n")
 	out.WriteString("\r" + ansiClearEOL + "\n")
 
 	// Confirmation prompt
-	prefix := "Type YES to confirm: "
 	prompt := s.centerText(dim(prefix) + s.renderInput(confirmation, cursor))
 	out.WriteString("\r" + ansiClearEOL + prompt + "\n")
 
 	// Fill remaining space
-	usedLines := 2 + len(markedItems) + 3 + 2 // header + items + blanks + prompt + footer
+	usedLines := 2 + len(markedItems) + 3 + 2 // header + items + warning + blank + prompt + footer
 	for i := usedLines; i < s.height-2; i++ {
 		out.WriteString("\r" + ansiClearEOL + "\n")
 	}
 
 	// Footer
 	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
-	out.WriteString("\r" + ansiClearEOL + s.centerText(dim("Enter: Confirm  Esc: Cancel")))
+	out.WriteString("\r" + ansiClearEOL + s.centerText(dim(s.dialogHints())))
 
 	out.WriteString(ansiShow)
 	out.WriteString(ansiReset)
@@ -956,54 +1095,36 @@ func (s *Selector) renderDeleteDialog(markedItems []Entry, confirmation string,
 	s.io.WriteString(out.String())
 }
 
-func (s *Selector) processDeleteConfirmation(markedItems []Entry, confirmation string) {
-	if confirmation == "YES" {
-		baseReal, err := filepath.EvalSymlinks(s.basePath)
-		if err != nil {
-			baseReal = s.basePath
-		}
-
-		paths := []DeletePath{}
-		for _, item := range markedItems {
-			targetReal, err := filepath.EvalSymlinks(item.Item.Path)
-			if err != nil {
-				targetReal = item.Item.Path
-			}
-			if !strings.HasPrefix(targetReal, baseReal+"/") {
-				s.deleteStatus = fmt.Sprintf("Safety check failed: %s not in %s", targetReal, baseReal)
-				return
-			}
-			paths = append(paths, DeletePath{
-				Path:     targetReal,
-				Basename: item.Item.Basename,
-			})
-		}
+// deleteMarked selects the deletion of markedItems
+func (s *Selector) deleteMarked(markedItems []Entry) {
+	paths, baseReal, ok := s.markedPaths(markedItems)
+	if !ok {
+		return
+	}
 
-		s.selected = &SelectionResult{
-			Type:     "delete",
-			Paths:    paths,
-			BasePath: baseReal,
-		}
+	s.selected = &SelectionResult{
+		Type:     "delete",
+		Paths:    paths,
+		BasePath: baseReal,
+	}
 
-		names := make([]string, len(paths))
-		for i, p := range paths {
-			names[i] = p.Basename
-		}
-		s.deleteStatus = "Deleted: " + strings.Join(names, ", ")
-		s.allTries = nil
-		s.matcher = nil
-		s.markedForDelete = nil
-		s.deleteMode = false
-	} else {
-		s.deleteStatus = "Delete cancelled"
-		s.markedForDelete = nil
-		s.deleteMode = false
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = p.Basename
 	}
+	s.setStatus("Deleted: " + strings.Join(names, ", "))
+	s.allTries = nil
+	s.resetMatches()
+	s.clearMarks()
+}
+
+func (s *Selector) cancelDelete() {
+	s.setStatus("Delete cancelled")
+	s.clearMarks()
 }
 
 func (s *Selector) runRenameDialog(entry Entry) {
-	s.deleteMode = false
-	s.markedForDelete = nil
+	s.clearMarks()
 
 	currentName := entry.Item.Basename
 	renameBuffer := currentName
@@ -1014,80 +1135,110 @@ func (s *Selector) runRenameDialog(entry Entry) {
 		s.renderRenameDialog(currentName, renameBuffer, renameCursor, renameError)
 
 		key := s.readKey()
-		switch key {
-		case "\r", "\n":
+		switch s.dialogAction(key) {
+		case actSelect:
 			result := s.finalizeRename(entry, renameBuffer)
 			if result == "" {
 				return
 			}
 			renameError = result
 
-		case "\x1b", "\x03":
+		case actCancel:
 			s.NeedsRedraw = true
 			return
 
-		case "\x7f", "\x08": // Backspace (DEL) or Ctrl-H
-			if renameCursor > 0 {
-				renameBuffer = renameBuffer[:renameCursor-1] + renameBuffer[renameCursor:]
-				renameCursor--
+		default:
+			var edited bool
+			renameBuffer, renameCursor, edited = s.editLine(renameBuffer, renameCursor, key, isRenamePrintable)
+			if edited {
+				renameError = ""
 			}
-			renameError = ""
+		}
+	}
+}
 
-		case "\x01": // Ctrl-A
-			renameCursor = 0
+// dialogAction returns what key does in a dialog: the insert mode action,
+// where leaving insert mode cancels the dialog
+func (s *Selector) dialogAction(key string) string {
+	action := s.keymap.action(modeInsert, key)
+	if action == actNormalMode {
+		return actCancel
+	}
+	return action
+}
 
-		case "\x05": // Ctrl-E
-			renameCursor = len(renameBuffer)
+// editLine applies a line-editing key to buffer. It reports whether the
+// text changed; cursor movement alone does not count.
+func (s *Selector) editLine(buffer string, cursor int, key string, printable func(rune) bool) (string, int, bool) {
+	switch s.keymap.action(modeInsert, key) {
+	case actDeleteCharBack:
+		if cursor > 0 {
+			prev := prevRune(buffer, cursor)
+			buffer = buffer[:prev] + buffer[cursor:]
+			cursor = prev
+		}
+		return buffer, cursor, true
 
-		case "\x02": // Ctrl-B
-			if renameCursor > 0 {
-				renameCursor--
-			}
+	case actLineStart:
+		return buffer, 0, false
 
-		case "\x06": // Ctrl-F
-			if renameCursor < len(renameBuffer) {
-				renameCursor++
-			}
+	case actLineEnd:
+		return buffer, len(buffer), false
 
-		case "\x0b": // Ctrl-K
-			renameBuffer = renameBuffer[:renameCursor]
-			renameError = ""
+	case actCharBack:
+		if cursor > 0 {
+			cursor = prevRune(buffer, cursor)
+		}
+		return buffer, cursor, false
 
-		case "\x17": // Ctrl-W
-			if renameCursor > 0 {
-				pos := renameCursor - 1
-				for pos > 0 && !isWordChar(rune(renameBuffer[pos])) {
-					pos--
-				}
-				for pos > 0 && isWordChar(rune(renameBuffer[pos-1])) {
-					pos--
-				}
-				renameBuffer = renameBuffer[:pos] + renameBuffer[renameCursor:]
-				renameCursor = pos
-			}
-			renameError = ""
+	case actCharForward:
+		if cursor < len(buffer) {
+			cursor = nextRune(buffer, cursor)
+		}
+		return buffer, cursor, false
 
-		default:
-			if len(key) == 1 && isRenamePrintable(key[0]) {
-				renameBuffer = renameBuffer[:renameCursor] + key + renameBuffer[renameCursor:]
-				renameCursor++
-				renameError = ""
+	case actKillLine:
+		return buffer[:cursor], cursor, true
+
+	case actClearInput:
+		return "", 0, true
+
+	case actDeleteWordBack:
+		if cursor > 0 {
+			pos := cursor - 1
+			for pos > 0 && !isWordChar(rune(buffer[pos])) {
+				pos--
 			}
+			for pos > 0 && isWordChar(rune(buffer[pos-1])) {
+				pos--
+			}
+			buffer = buffer[:pos] + buffer[cursor:]
+			cursor = pos
 		}
+		return buffer, cursor, true
+	}
+
+	if text, ok := strings.CutPrefix(key, KeyPaste); ok {
+		buffer, cursor = insertText(buffer, cursor, text, printable)
+		return buffer, cursor, true
+	}
+	if r, ok := typedRune(key); ok && printable(r) {
+		return buffer[:cursor] + key + buffer[cursor:], cursor + len(key), true
 	}
+	return buffer, cursor, false
 }
 
 func (s *Selector) renderRenameDialog(currentName, renameBuffer string, renameCursor int, renameError string) {
 	var out strings.Builder
-	out.WriteString(ansiHome)
+	out.WriteString(s.home())
 
 	// Header
-	header := s.centerText("✏️" + accent("  Rename directory"))
+	header := s.centerText(s.icon("✏️", "") + accent("  Rename directory"))
 	out.WriteString("\r" + ansiClearEOL + header + "\n")
 	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
 
 	// Current name
-	out.WriteString("\r" + ansiClearEOL + "📁 " + currentName + "\n")
+	out.WriteString("\r" + ansiClearEOL + s.icon("📁", "/") + " " + currentName + "\n")
 
 	// Blank lines
 	out.WriteString("\r" + ansiClearEOL + "\n")
@@ -1098,6 +1249,13 @@ func (s *Selector) renderRenameDialog(currentName, renameBuffer string, renameCu
 	prompt := s.centerText(dim(prefix) + s.renderInput(renameBuffer, renameCursor))
 	out.WriteString("\r" + ansiClearEOL + prompt + "\n")
 
+	// Sanitized name preview, only when it differs from the input
+	usedLines := 6
+	if slugged := s.config.Slugify(renameBuffer); slugged != "" && slugged != renameBuffer && !strings.Contains(renameBuffer, "/") {
+		out.WriteString("\r" + ansiClearEOL + s.centerText(dim("Saved as: ")+slugged) + "\n")
+		usedLines++
+	}
+
 	// Error message
 	if renameError != "" {
 		out.WriteString("\r" + ansiClearEOL + "\n")
@@ -1105,7 +1263,6 @@ func (s *Selector) renderRenameDialog(currentName, renameBuffer string, renameCu
 	}
 
 	// Fill remaining space
-	usedLines := 6
 	if renameError != "" {
 		usedLines += 2
 	}
@@ -1115,7 +1272,7 @@ func (s *Selector) renderRenameDialog(currentName, renameBuffer string, renameCu
 
 	// Footer
 	out.WriteString("\r" + ansiClearEOL + dim(strings.Repeat("─", s.width-1)) + "\n")
-	out.WriteString("\r" + ansiClearEOL + s.centerText(dim("Enter: Confirm  Esc: Cancel")))
+	out.WriteString("\r" + ansiClearEOL + s.centerText(dim(s.dialogHints())))
 
 	out.WriteString(ansiShow)
 	out.WriteString(ansiReset)
@@ -1124,20 +1281,22 @@ func (s *Selector) renderRenameDialog(currentName, renameBuffer string, renameCu
 }
 
 func (s *Selector) finalizeRename(entry Entry, renameBuffer string) string {
-	newName := strings.TrimSpace(strings.ReplaceAll(renameBuffer, " ", "-"))
+	if strings.Contains(renameBuffer, "/") {
+		return "Name cannot contain /"
+	}
+	newName := s.config.Slugify(renameBuffer)
 	oldName := entry.Item.Basename
 
 	if newName == "" {
 		return "Name cannot be empty"
 	}
-	if strings.Contains(newName, "/") {
-		return "Name cannot contain /"
-	}
 	if newName == oldName {
 		s.NeedsRedraw = true
 		return "" // No change, just exit
 	}
-	if _, err := os.Stat(filepath.Join(s.basePath, newName)); err == nil {
+	// Nested tries are renamed in place, inside their year/month directory
+	parentDir := filepath.Dir(entry.Item.Path)
+	if _, err := os.Stat(filepath.Join(parentDir, newName)); err == nil {
 		return fmt.Sprintf("Directory exists: %s", newName)
 	}
 
@@ -1145,7 +1304,7 @@ func (s *Selector) finalizeRename(entry Entry, renameBuffer string) string {
 		Type:     "rename",
 		OldName:  oldName,
 		NewName:  newName,
-		BasePath: s.basePath,
+		BasePath: parentDir,
 	}
 	return ""
 }
@@ -1156,14 +1315,24 @@ func isWordChar(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
 }
 
-func isPrintable(b byte) bool {
-	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') ||
-		(b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == ' '
+// isPrintable accepts name characters and the query operators ' ^ $ ! | \
+func isPrintable(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' || r == ' ' ||
+		r == '\'' || r == '^' || r == '$' || r == '!' || r == '|' || r == '\\' ||
+		isLetterBeyondASCII(r)
 }
 
-func isRenamePrintable(b byte) bool {
-	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') ||
-		(b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == ' ' || b == '/'
+func isRenamePrintable(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' || r == ' ' || r == '/' ||
+		isLetterBeyondASCII(r)
+}
+
+// isLetterBeyondASCII accepts accented and non-Latin letters, digits and
+// the combining marks that follow them
+func isLetterBeyondASCII(r rune) bool {
+	return r >= utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
 }
 
 func indexOf(slice []string, item string) int {
@@ -1184,27 +1353,27 @@ func containsInt(slice []int, item int) bool {
 	return false
 }
 
-func visibleLen(s string) int {
-	// Strip ANSI codes
-	ansiRe := regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
-	stripped := ansiRe.ReplaceAllString(s, "")
-
-	// Count visible width (emoji = 2, others = 1)
-	width := 0
-	for _, r := range stripped {
-		if r >= 0xFE00 && r <= 0xFE0F {
-			// Zero-width variation selectors
-			continue
-		} else if r >= 0x1F300 && r <= 0x1FAFF {
-			// Emoji
-			width += 2
-		} else {
-			width++
-		}
+var (
+	ansiRe      = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+	ansiTokenRe = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|\{/?[a-z0-9]+\}`)
+)
+
+// codeRe matches what takes no room on screen: ANSI codes, and the style
+// tokens while they are shown literally
+func codeRe() *regexp.Regexp {
+	if rawTokens {
+		return ansiTokenRe
 	}
-	return width
+	return ansiRe
+}
+
+// visibleLen returns the terminal cells s occupies, ignoring ANSI codes
+func visibleLen(s string) int {
+	return cellwidth.String(codeRe().ReplaceAllString(s, ""))
 }
 
+// truncateWithAnsi cuts text to at most maxLen cells, keeping its ANSI
+// codes and never splitting a character cluster
 func truncateWithAnsi(text string, maxLen int) string {
 	if visibleLen(text) <= maxLen {
 		return text
@@ -1212,28 +1381,25 @@ func truncateWithAnsi(text string, maxLen int) string {
 
 	visibleCount := 0
 	var result strings.Builder
-	inAnsi := false
-
-	for _, ch := range text {
-		if ch == '\x1b' {
-			inAnsi = true
-			result.WriteRune(ch)
-		} else if inAnsi {
-			result.WriteRune(ch)
-			if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') {
-				inAnsi = false
-			}
-		} else {
-			cw := 1
-			if ch >= 0x1F300 && ch <= 0x1FAFF {
-				cw = 2
-			}
-			if visibleCount+cw > maxLen {
-				break
+	for text != "" {
+		if loc := codeRe().FindStringIndex(text); loc != nil && loc[0] == 0 {
+			result.WriteString(text[:loc[1]])
+			text = text[loc[1]:]
+			continue
+		}
+		// Measure up to the next escape sequence
+		end := len(text)
+		if loc := codeRe().FindStringIndex(text); loc != nil {
+			end = loc[0]
+		}
+		for _, c := range cellwidth.Clusters(text[:end]) {
+			if visibleCount+c.Width > maxLen {
+				return strings.TrimRight(result.String(), " ")
 			}
-			result.WriteRune(ch)
-			visibleCount += cw
+			result.WriteString(c.Text)
+			visibleCount += c.Width
 		}
+		text = text[end:]
 	}
 
 	return strings.TrimRight(result.String(), " ")
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/amulcse/try/internal/watch"
+)
+
+// watchTries watches the directories listed for the index, so tries
+// created, deleted or touched elsewhere show up while the selector is open.
+// Tests drive the list themselves and are not watched.
+func (s *Selector) watchTries() {
+	if s.testHadKeys || s.testRenderOnce {
+		return
+	}
+	dirs := make([]string, 0, len(s.index.Dirs))
+	for rel := range s.index.Dirs {
+		dirs = append(dirs, filepath.Join(s.basePath, filepath.FromSlash(rel)))
+	}
+	sort.Strings(dirs)
+	if s.watcher != nil && equalStrings(dirs, s.watched) {
+		return
+	}
+	s.stopWatching()
+	s.watcher = watch.New(dirs)
+	s.watched = dirs
+}
+
+// stopWatching closes the watcher, if any
+func (s *Selector) stopWatching() {
+	if s.watcher != nil {
+		s.watcher.Close()
+		s.watcher = nil
+	}
+}
+
+// watchChanges returns the watcher's signal, or nil, which never fires,
+// when nothing is watched
+func (s *Selector) watchChanges() <-chan struct{} {
+	if s.watcher == nil {
+		return nil
+	}
+	return s.watcher.Changes()
+}
+
+// pollWatch rescans the root in the background after the watcher saw a
+// change. Changes seen during a scan wait for it to finish, then rescan.
+func (s *Selector) pollWatch() {
+	if s.triesChanged && s.reconciling == nil && s.index != nil {
+		s.triesChanged = false
+		s.startReconcile(s.index)
+	}
+}
+
+// followCursor moves the cursor back to the try it was on before the list
+// was rebuilt, once the matcher caught up
+func (s *Selector) followCursor(tries []Entry) {
+	if s.follow == "" || s.pending != nil {
+		return
+	}
+	for i, t := range tries {
+		if t.Item.Path == s.follow {
+			s.cursorPos = i
+			break
+		}
+	}
+	s.follow = ""
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// notifyMask selects the inotify events that change the list of tries.
+// IN_ATTRIB on a watched directory also covers its entries being touched.
+const notifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+// notifier reads inotify events. Any event is a change, so their contents
+// are not decoded.
+type notifier struct {
+	file    *os.File
+	changes chan struct{}
+}
+
+func newNotifier(dirs []string) (Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if _, err := unix.InotifyAddWatch(fd, dir, notifyMask); err != nil {
+			unix.Close(fd)
+			return nil, err
+		}
+	}
+
+	// A non-blocking descriptor goes through the runtime poller, so Close
+	// unblocks the pending Read
+	n := &notifier{file: os.NewFile(uintptr(fd), "inotify"), changes: make(chan struct{}, 1)}
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if _, err := n.file.Read(buf); err != nil {
+				return
+			}
+			signal(n.changes)
+		}
+	}()
+	return n, nil
+}
+
+func (n *notifier) Changes() <-chan struct{} {
+	return n.changes
+}
+
+func (n *notifier) Close() error {
+	return n.file.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+func newNotifier(dirs []string) (Watcher, error) {
+	return nil, errors.New("watch: no notifications on this platform")
+}
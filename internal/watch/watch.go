@@ -0,0 +1,89 @@
+// Package watch reports changes to directories: through inotify where the
+// platform has it, and by polling their modification times elsewhere
+package watch
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// PollInterval is how often the polling fallback stats the directories
+const PollInterval = time.Second
+
+// Watcher reports entries created, deleted, renamed or touched in a set of
+// directories. Bursts of changes are coalesced into one signal.
+type Watcher interface {
+	Changes() <-chan struct{}
+	Close() error
+}
+
+// New watches dirs, polling them when notifications are unavailable
+func New(dirs []string) Watcher {
+	if w, err := newNotifier(dirs); err == nil {
+		return w
+	}
+	return newPoller(dirs, PollInterval)
+}
+
+// signal wakes the reader of changes without blocking; an unread signal
+// already covers the change
+func signal(changes chan struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// poller compares the modification times of the directories every
+// interval. It sees entries created, deleted or renamed, not touched.
+type poller struct {
+	changes chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newPoller(dirs []string, interval time.Duration) *poller {
+	p := &poller{changes: make(chan struct{}, 1), done: make(chan struct{})}
+	mtimes := make(map[string]time.Time, len(dirs))
+	for _, dir := range dirs {
+		mtimes[dir] = modTime(dir)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+			}
+			for dir, mtime := range mtimes {
+				if now := modTime(dir); !now.Equal(mtime) {
+					mtimes[dir] = now
+					signal(p.changes)
+				}
+			}
+		}
+	}()
+	return p
+}
+
+func (p *poller) Changes() <-chan struct{} {
+	return p.changes
+}
+
+func (p *poller) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// modTime returns the modification time of dir, or the zero time once it
+// is gone
+func modTime(dir string) time.Time {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -75,6 +75,9 @@ export TEST_ROOT=$(mktemp -d)
 export TEST_TRIES="$TEST_ROOT/tries"
 mkdir -p "$TEST_TRIES"
 
+# Keep the user's config file out of the tests (tests opt in via TRY_CONFIG)
+export TRY_CONFIG="$TEST_ROOT/config.json"
+
 # Create test directories with different mtimes
 mkdir -p "$TEST_TRIES/2025-11-01-alpha"
 mkdir -p "$TEST_TRIES/2025-11-15-beta"
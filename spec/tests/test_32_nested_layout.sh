@@ -0,0 +1,86 @@
+# Nested layout tests
+# Spec: tries may live in <root>/YYYY/MM/<name>; migrate-layout moves them
+
+section "nested-layout"
+
+strip_ansi() {
+    sed 's/\x1b\[[0-9;]*[a-zA-Z]//g' | sed 's/\x1b\[[?][0-9]*[a-zA-Z]//g'
+}
+
+NEST_TEST_DIR=$(mktemp -d)
+NEST_CONFIG="$NEST_TEST_DIR/config.json"
+NEST_TRIES="$NEST_TEST_DIR/tries"
+mkdir -p "$NEST_TRIES/2025-01-21-redis"
+mkdir -p "$NEST_TRIES/2024-12-03-webapp"
+echo '{"layout": "nested"}' > "$NEST_CONFIG"
+
+# Test: migrate-layout nested emits moves into year/month directories
+output=$(try_run --path="$NEST_TRIES" exec migrate-layout nested 2>/dev/null)
+if echo "$output" | grep -q "mv '2025-01-21-redis' '2025/01/2025-01-21-redis'"; then
+    pass
+else
+    fail "migrate-layout nested should move into YYYY/MM" "mv into 2025/01" "$output" "command_line.md"
+fi
+
+# Apply the migration for the following tests
+(eval "$output") >/dev/null 2>&1
+
+# Test: migration created the nested directories
+if [ -d "$NEST_TRIES/2025/01/2025-01-21-redis" ] && [ -d "$NEST_TRIES/2024/12/2024-12-03-webapp" ]; then
+    pass
+else
+    fail "migrate-layout nested should create YYYY/MM directories" "2025/01/2025-01-21-redis" "$(find "$NEST_TRIES")" "command_line.md"
+fi
+
+# Test: selector lists nested tries with their relative path
+output=$(TRY_CONFIG="$NEST_CONFIG" try_run --path="$NEST_TRIES" --and-exit exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "2025/01/2025-01-21-redis"; then
+    pass
+else
+    fail "Selector should show nested relative path" "2025/01/2025-01-21-redis" "$output" "tui_spec.md"
+fi
+
+# Test: relative path is fuzzy-matchable
+output=$(TRY_CONFIG="$NEST_CONFIG" try_run --path="$NEST_TRIES" --and-keys='2,0,2,4,w,e,b,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "cd '.*2024/12/2024-12-03-webapp'"; then
+    pass
+else
+    fail "Relative path should be matchable" "cd into 2024/12/2024-12-03-webapp" "$output" "fuzzy_matching.md"
+fi
+
+# Test: create new goes into the current year/month
+output=$(TRY_CONFIG="$NEST_CONFIG" try_run --path="$NEST_TRIES" --and-keys='n,e,w,CTRL-T' exec 2>/dev/null)
+BUCKET=$(date +%Y/%m)
+if echo "$output" | grep -q "mkdir -p '.*$BUCKET/$(date +%Y-%m-%d)-new'"; then
+    pass
+else
+    fail "Create new should use YYYY/MM directory" "$BUCKET/$(date +%Y-%m-%d)-new" "$output" "command_line.md"
+fi
+
+# Test: delete uses the path relative to the root
+output=$(TRY_CONFIG="$NEST_CONFIG" try_run --path="$NEST_TRIES" --and-keys='r,e,d,i,s,CTRL-D,ENTER' --and-confirm=YES exec 2>/dev/null)
+if echo "$output" | grep -q "rm -rf '2025/01/2025-01-21-redis'"; then
+    pass
+else
+    fail "Delete should use nested relative path" "rm -rf '2025/01/2025-01-21-redis'" "$output" "delete_spec.md"
+fi
+
+# Test: migrate-layout flat moves tries back to the root
+output=$(try_run --path="$NEST_TRIES" exec migrate-layout flat 2>/dev/null)
+(eval "$output") >/dev/null 2>&1
+if [ -d "$NEST_TRIES/2025-01-21-redis" ] && [ ! -d "$NEST_TRIES/2025" ]; then
+    pass
+else
+    fail "migrate-layout flat should restore flat layout" "2025-01-21-redis at root" "$(find "$NEST_TRIES")" "command_line.md"
+fi
+
+# Test: migrate-layout without target is an error
+output=$(try_run --path="$NEST_TRIES" exec migrate-layout 2>&1)
+if [ $? -ne 0 ] && echo "$output" | grep -q "Usage: try migrate-layout"; then
+    pass
+else
+    fail "migrate-layout without target should fail" "usage error" "$output" "command_line.md"
+fi
+
+# Cleanup
+rm -rf "$NEST_TEST_DIR"
@@ -0,0 +1,95 @@
+# Name sanitization tests
+# Spec: names of created and renamed tries are slugified
+
+section "name-sanitization"
+
+strip_ansi() {
+    sed 's/\x1b\[[0-9;]*[a-zA-Z]//g' | sed 's/\x1b\[[?][0-9]*[a-zA-Z]//g'
+}
+
+SLUG_TEST_DIR=$(mktemp -d)
+mkdir -p "$SLUG_TEST_DIR/2025-11-01-existing"
+TODAY=$(date +%Y-%m-%d)
+
+# Test: clone custom name replaces shell-hostile characters
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo 'my:weird*name' 2>/dev/null)
+if echo "$output" | grep -q "/my-weird-name'"; then
+    pass
+else
+    fail "Clone name should be sanitized" "my-weird-name" "$output" "command_line.md"
+fi
+
+# Test: leading dashes and trailing dots are trimmed
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo '--name...' 2>/dev/null)
+if echo "$output" | grep -q "/name'"; then
+    pass
+else
+    fail "Leading dashes and trailing dots should be trimmed" "name" "$output" "command_line.md"
+fi
+
+# Test: both ends are trimmed of the same separators
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo '_name_' 2>/dev/null)
+if echo "$output" | grep -q "/name'"; then
+    pass
+else
+    fail "Underscores should be trimmed from both ends" "name" "$output" "command_line.md"
+fi
+
+# Test: diacritics are folded to ASCII
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo 'café crème' 2>/dev/null)
+if echo "$output" | grep -q "/cafe-creme'"; then
+    pass
+else
+    fail "Diacritics should be folded" "cafe-creme" "$output" "command_line.md"
+fi
+
+# Test: a name with nothing usable is rejected
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo '***' 2>&1)
+if [ $? -ne 0 ] && echo "$output" | grep -q "invalid name"; then
+    pass
+else
+    fail "Unusable name should be rejected" "invalid name error" "$output" "command_line.md"
+fi
+
+# Test: the rejected name is the one that failed
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone https://github.com/user/repo '***' 2>&1)
+if echo "$output" | grep -q "invalid name: \*\*\*$"; then
+    pass
+else
+    fail "Rejection should name the custom name" "invalid name: ***" "$output" "command_line.md"
+fi
+
+# Test: a name derived from the URL is named when rejected
+output=$(try_run --path="$SLUG_TEST_DIR" exec clone 'https://github.com/__/..' 2>&1)
+if [ $? -ne 0 ] && echo "$output" | grep -q "invalid name: __-\.\.$"; then
+    pass
+else
+    fail "Rejection should name the name from the URL" "invalid name: __-.." "$output" "command_line.md"
+fi
+
+# Test: create-new row previews the sanitized name
+output=$(try_run --path="$SLUG_TEST_DIR" --and-exit --and-type='my project..' exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "Create new: $TODAY-my-project$"; then
+    pass
+else
+    fail "Create new should preview sanitized name" "$TODAY-my-project" "$output" "tui_spec.md"
+fi
+
+# Test: create-new uses the sanitized name
+output=$(try_run --path="$SLUG_TEST_DIR" --and-keys='m,y,.,.,CTRL-T' exec 2>/dev/null)
+if echo "$output" | grep -q "mkdir -p '.*/$TODAY-my'"; then
+    pass
+else
+    fail "Create new should use sanitized name" "$TODAY-my" "$output" "tui_spec.md"
+fi
+
+# Test: rename sanitizes the new name
+output=$(try_run --path="$SLUG_TEST_DIR" --and-keys='CTRL-R,CTRL-A,CTRL-K,-,-,n,e,w,n,a,m,e,.,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "mv '2025-11-01-existing' 'newname'"; then
+    pass
+else
+    fail "Rename should sanitize new name" "mv to newname" "$output" "tui_spec.md"
+fi
+
+# Cleanup
+rm -rf "$SLUG_TEST_DIR"
@@ -0,0 +1,104 @@
+# Lifecycle hook tests
+# Spec: hooks from config and <root>/.try/hooks run around create, delete, rename and cd
+
+section "hooks"
+
+HOOK_TEST_DIR=$(mktemp -d)
+HOOK_TRIES="$HOOK_TEST_DIR/tries"
+HOOK_CONFIG="$HOOK_TEST_DIR/config.json"
+
+# try_run also captures the TUI (stderr), so keep only the emitted script
+script_only() {
+    sed -n 's/.*\(# if you can read this\)/\1/; /^# if you can read this/,$p'
+}
+mkdir -p "$HOOK_TRIES/.try/hooks" "$HOOK_TRIES/2025-11-01-victim"
+cat > "$HOOK_CONFIG" <<'JSON'
+{"hooks": {"post-create": ["echo created"], "post-cd": ["echo entered"]}}
+JSON
+
+# Test: post-create hook from config runs after mkdir
+output=$(TRY_CONFIG="$HOOK_CONFIG" try_run --path="$HOOK_TRIES" --and-keys='n,e,w,CTRL-T' exec 2>/dev/null)
+if echo "$output" | grep -q "TRY_HOOK=post-create' 'TRY_ACTION=mkdir'.*sh -c 'echo created'"; then
+    pass
+else
+    fail "post-create hook should run after mkdir" "post-create with TRY_ACTION=mkdir" "$output" "command_line.md"
+fi
+
+# Test: post-cd hook runs after selecting an existing try
+output=$(TRY_CONFIG="$HOOK_CONFIG" try_run --path="$HOOK_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "TRY_HOOK=post-cd'.*sh -c 'echo entered'" && ! echo "$output" | grep -q "post-create"; then
+    pass
+else
+    fail "Plain cd should run only post-cd hooks" "post-cd without post-create" "$output" "command_line.md"
+fi
+
+# Test: hooks get the try directory
+output=$(TRY_CONFIG="$HOOK_CONFIG" try_run --path="$HOOK_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "'TRY_DIR=$HOOK_TRIES/2025-11-01-victim'"; then
+    pass
+else
+    fail "Hooks should receive TRY_DIR" "TRY_DIR=$HOOK_TRIES/2025-11-01-victim" "$output" "command_line.md"
+fi
+
+# Test: failing executable pre-delete hook aborts the delete
+printf '#!/bin/sh\nexit 1\n' > "$HOOK_TRIES/.try/hooks/pre-delete"
+chmod +x "$HOOK_TRIES/.try/hooks/pre-delete"
+output=$(try_run --path="$HOOK_TRIES" --and-keys='CTRL-D,ENTER' --and-confirm=YES exec 2>/dev/null)
+(eval "$(echo "$output" | script_only)") >/dev/null 2>&1
+if [ -d "$HOOK_TRIES/2025-11-01-victim" ]; then
+    pass
+else
+    fail "Failing pre-delete hook should abort delete" "directory kept" "$output" "delete_spec.md"
+fi
+
+# Test: passing pre-delete hook lets the delete through
+printf '#!/bin/sh\nexit 0\n' > "$HOOK_TRIES/.try/hooks/pre-delete"
+output=$(try_run --path="$HOOK_TRIES" --and-keys='CTRL-D,ENTER' --and-confirm=YES exec 2>/dev/null)
+(eval "$(echo "$output" | script_only)") >/dev/null 2>&1
+if [ ! -d "$HOOK_TRIES/2025-11-01-victim" ]; then
+    pass
+else
+    fail "Passing pre-delete hook should allow delete" "directory removed" "$output" "delete_spec.md"
+fi
+
+# Test: post-rename hook gets old and new names
+mkdir -p "$HOOK_TRIES/2025-11-02-old"
+printf '#!/bin/sh\necho "$TRY_OLD_NAME -> $TRY_NEW_NAME"\n' > "$HOOK_TRIES/.try/hooks/post-rename"
+chmod +x "$HOOK_TRIES/.try/hooks/post-rename"
+output=$(try_run --path="$HOOK_TRIES" --and-keys='CTRL-R,x,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "'TRY_OLD_NAME=2025-11-02-old' 'TRY_NEW_NAME=2025-11-02-oldx'.*hooks/post-rename'"; then
+    pass
+else
+    fail "post-rename hook should get old and new names" "TRY_OLD_NAME and TRY_NEW_NAME" "$output" "command_line.md"
+fi
+
+# Test: no hooks, no hook lines
+output=$(try_run --path="$TEST_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if ! echo "$output" | grep -q "TRY_HOOK"; then
+    pass
+else
+    fail "No hook lines without hooks" "no TRY_HOOK" "$output" "command_line.md"
+fi
+
+# Test: in session mode, hooks run in the try before attaching, which blocks
+output=$(TMUX= TRY_CONFIG="$HOOK_CONFIG" try_run --path="$HOOK_TRIES" --tmux --and-keys='n,e,w,CTRL-T' exec 2>/dev/null)
+hook_line=$(echo "$output" | grep -n "TRY_HOOK=post-cd" | cut -d: -f1)
+attach_line=$(echo "$output" | grep -n "tmux attach-session" | cut -d: -f1)
+if [ -n "$hook_line" ] && [ -n "$attach_line" ] && [ "$hook_line" -lt "$attach_line" ] && echo "$output" | grep "TRY_HOOK=post-cd" | grep -q "( cd '$HOOK_TRIES/"; then
+    pass
+else
+    fail "hooks should run before tmux attach-session" "hook lines before attach-session" "$output" "command_line.md#session-mode"
+fi
+
+# Test: the same for zellij
+output=$(ZELLIJ= TRY_CONFIG="$HOOK_CONFIG" try_run --path="$HOOK_TRIES" --zellij --and-keys='ENTER' exec 2>/dev/null)
+hook_line=$(echo "$output" | grep -n "TRY_HOOK=post-cd" | cut -d: -f1)
+attach_line=$(echo "$output" | grep -n "zellij attach --create" | cut -d: -f1)
+if [ -n "$hook_line" ] && [ -n "$attach_line" ] && [ "$hook_line" -lt "$attach_line" ]; then
+    pass
+else
+    fail "hooks should run before zellij attach" "hook lines before zellij attach" "$output" "command_line.md#session-mode"
+fi
+
+# Cleanup
+rm -rf "$HOOK_TEST_DIR"
@@ -0,0 +1,83 @@
+# Per-try .tryrc activation tests
+# Spec: a trusted .tryrc is sourced after cd; untrusted files never are
+
+section "tryrc"
+
+RC_TEST_DIR=$(mktemp -d)
+RC_TRIES="$RC_TEST_DIR/tries"
+RC_CONFIG="$RC_TEST_DIR/config.json"
+mkdir -p "$RC_TRIES/2025-11-01-envy"
+echo 'export ENVY=1' > "$RC_TRIES/2025-11-01-envy/.tryrc"
+echo '{"tryrc": {"enabled": true}}' > "$RC_CONFIG"
+
+# Test: untrusted .tryrc is not sourced
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run --path="$RC_TRIES" --and-keys='ENTER' exec 2>&1)
+if ! echo "$output" | grep -q "eval '" && echo "$output" | grep -q "is not trusted"; then
+    pass
+else
+    fail "Untrusted .tryrc should not be sourced" "no eval line, not trusted warning" "$output" "command_line.md"
+fi
+
+# Test: try trust records approval
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run trust "$RC_TRIES/2025-11-01-envy" 2>&1)
+if [ -s "$RC_TEST_DIR/data/try/trusted" ]; then
+    pass
+else
+    fail "try trust should record approval" "trusted file written" "$output" "command_line.md"
+fi
+
+# Test: trusted .tryrc is run after cd
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run --path="$RC_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "cd '$RC_TRIES/2025-11-01-envy'" && echo "$output" | grep -q "eval 'export ENVY=1"; then
+    pass
+else
+    fail "Trusted .tryrc should be sourced" "eval 'export ENVY=1" "$output" "command_line.md"
+fi
+
+# Test: content swapped after the check is not what runs
+echo 'export ENVY=swapped' > "$RC_TRIES/2025-11-01-envy/.tryrc"
+envy=$(eval "$(echo "$output" | sed -n 's/.*\(# if you can read this\)/\1/; /^# if you can read this/,$p')" >/dev/null 2>&1; echo "$ENVY")
+if [ "$envy" = "1" ]; then
+    pass
+else
+    fail "The script should run the content that was trusted" "ENVY=1" "ENVY=$envy" "command_line.md"
+fi
+echo 'export ENVY=1' > "$RC_TRIES/2025-11-01-envy/.tryrc"
+
+# Test: session mode leaves .tryrc to the session's shell
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" TMUX= try_run --path="$RC_TRIES" --tmux --and-keys='ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "tmux attach-session" && ! echo "$output" | grep -q "eval '"; then
+    pass
+else
+    fail "Session mode should not source .tryrc" "attach without eval line" "$output" "command_line.md#session-mode"
+fi
+
+# Test: changing the file revokes trust
+echo 'export ENVY=2' >> "$RC_TRIES/2025-11-01-envy/.tryrc"
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run --path="$RC_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if ! echo "$output" | grep -q "eval '"; then
+    pass
+else
+    fail "Changed .tryrc should need new approval" "no eval line" "$output" "command_line.md"
+fi
+
+# Test: activation is off unless enabled
+XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run trust "$RC_TRIES/2025-11-01-envy" >/dev/null 2>&1
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" try_run --path="$RC_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if ! echo "$output" | grep -q "eval '"; then
+    pass
+else
+    fail ".tryrc should not be sourced when disabled" "no eval line" "$output" "command_line.md"
+fi
+
+# Test: try untrust revokes approval
+XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run untrust "$RC_TRIES/2025-11-01-envy" >/dev/null 2>&1
+output=$(XDG_DATA_HOME="$RC_TEST_DIR/data" TRY_CONFIG="$RC_CONFIG" try_run --path="$RC_TRIES" --and-keys='ENTER' exec 2>/dev/null)
+if ! echo "$output" | grep -q "eval '"; then
+    pass
+else
+    fail "try untrust should revoke approval" "no eval line" "$output" "command_line.md"
+fi
+
+# Cleanup
+rm -rf "$RC_TEST_DIR"
@@ -0,0 +1,65 @@
+# Alternate selection action tests
+# Spec: Ctrl-O edits, Ctrl-X opens tmux, Alt-Enter prints the path
+
+section "actions"
+
+ACT_TEST_DIR=$(mktemp -d)
+mkdir -p "$ACT_TEST_DIR/2025-11-01-acting"
+
+# Test: Ctrl-O opens the try in $EDITOR
+output=$(VISUAL= EDITOR=myeditor try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-O' exec 2>/dev/null)
+if echo "$output" | grep -q "myeditor '$ACT_TEST_DIR/2025-11-01-acting'"; then
+    pass
+else
+    fail "Ctrl-O should open try in \$EDITOR" "myeditor <path>" "$output" "tui_spec.md"
+fi
+
+# Test: $VISUAL wins over $EDITOR
+output=$(VISUAL=myvisual EDITOR=myeditor try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-O' exec 2>/dev/null)
+if echo "$output" | grep -q "myvisual '"; then
+    pass
+else
+    fail "Ctrl-O should prefer \$VISUAL" "myvisual <path>" "$output" "tui_spec.md"
+fi
+
+# Test: Ctrl-X outside tmux creates or attaches a session named after the try
+output=$(TMUX= try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-X' exec 2>/dev/null)
+if echo "$output" | grep -q "tmux new-session -A -s '2025-11-01-acting' -c '$ACT_TEST_DIR/2025-11-01-acting'"; then
+    pass
+else
+    fail "Ctrl-X should open a tmux session" "tmux new-session -A -s" "$output" "tui_spec.md"
+fi
+
+# Test: Ctrl-X inside tmux opens a window
+output=$(TMUX=/tmp/fake,1,0 try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-X' exec 2>/dev/null)
+if echo "$output" | grep -q "tmux new-window -n '2025-11-01-acting'"; then
+    pass
+else
+    fail "Ctrl-X inside tmux should open a window" "tmux new-window" "$output" "tui_spec.md"
+fi
+
+# Test: Alt-Enter prints the path without cd
+output=$(try_run --path="$ACT_TEST_DIR" --and-keys='ALT-ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "^echo '$ACT_TEST_DIR/2025-11-01-acting'" && ! echo "$output" | grep -q "cd '"; then
+    pass
+else
+    fail "Alt-Enter should print the path" "echo <path>, no cd" "$output" "tui_spec.md"
+fi
+
+# Test: actions can be rebound and unbound in the config
+echo '{"actions": {"ctrl-x": "", "ctrl-e": "print"}}' > "$ACT_TEST_DIR/config.json"
+output=$(TRY_CONFIG="$ACT_TEST_DIR/config.json" try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-X' exec 2>/dev/null)
+if ! echo "$output" | grep -q "tmux"; then
+    pass
+else
+    fail "Unbound Ctrl-X should not open tmux" "no tmux" "$output" "tui_spec.md"
+fi
+output=$(TRY_CONFIG="$ACT_TEST_DIR/config.json" try_run --path="$ACT_TEST_DIR" --and-keys='CTRL-E' exec 2>/dev/null)
+if echo "$output" | grep -q "^echo '$ACT_TEST_DIR/2025-11-01-acting'"; then
+    pass
+else
+    fail "Configured key should trigger its action" "echo <path>" "$output" "tui_spec.md"
+fi
+
+# Cleanup
+rm -rf "$ACT_TEST_DIR"
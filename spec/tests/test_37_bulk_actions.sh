@@ -0,0 +1,146 @@
+# Multi-select and bulk action tests
+# Spec: Tab marks tries; Enter opens a menu of actions for the marked set
+
+section "bulk-actions"
+
+strip_ansi() {
+    sed 's/\x1b\[[0-9;]*[a-zA-Z]//g' | sed 's/\x1b\[[?][0-9]*[a-zA-Z]//g'
+}
+
+BULK_TEST_DIR=$(mktemp -d)
+BULK_OTHER_DIR=$(mktemp -d)
+mkdir -p "$BULK_TEST_DIR/2025-11-01-first"
+mkdir -p "$BULK_TEST_DIR/2025-11-02-second"
+mkdir -p "$BULK_TEST_DIR/2025-11-03-third"
+touch -t 202511031200 "$BULK_TEST_DIR/2025-11-03-third"
+touch -t 202511021200 "$BULK_TEST_DIR/2025-11-02-second"
+touch -t 202511011200 "$BULK_TEST_DIR/2025-11-01-first"
+
+# Test: Tab marks entries and shows the mark mode footer
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,TAB' exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "MARK MODE  2 marked"; then
+    pass
+else
+    fail "Tab should mark entries" "MARK MODE  2 marked" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: marked entries show a pin
+if echo "$output" | grep -q "📌"; then
+    pass
+else
+    fail "Marked entries should show a pin" "📌" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: Enter with marks opens the action menu in the footer
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,ENTER' exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "d: Delete.*a: Archive.*t: Tag.*m: Move.*e: Export"; then
+    pass
+else
+    fail "Enter should show the action menu" "d: Delete ... e: Export" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: archive moves marked tries into .archive
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,TAB,ENTER,a' exec 2>/dev/null)
+if echo "$output" | grep -q "mv '2025-11-03-third' '$BULK_TEST_DIR/.archive/2025-11-03-third'" && \
+   echo "$output" | grep -q "mv '2025-11-02-second' '$BULK_TEST_DIR/.archive/2025-11-02-second'"; then
+    pass
+else
+    fail "Archive should move marked tries to .archive" "mv into .archive" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: move sends marked tries to another root
+output=$(cd "$BULK_OTHER_DIR/.." && try_run --path="$BULK_TEST_DIR" --and-keys="TAB,ENTER,m,$(basename "$BULK_OTHER_DIR" | sed 's/./&,/g')ENTER" exec 2>/dev/null)
+if echo "$output" | grep -q "mv '2025-11-03-third' '$BULK_OTHER_DIR/2025-11-03-third'"; then
+    pass
+else
+    fail "Move should move marked tries to the target root" "mv into other root" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: export packs marked tries into a tarball
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,TAB,ENTER,e' exec 2>/dev/null)
+if echo "$output" | grep -q "tar -czf '.*try-export-.*\.tar\.gz' -C '$BULK_TEST_DIR' '2025-11-03-third' '2025-11-02-second'"; then
+    pass
+else
+    fail "Export should tar marked tries" "tar -czf ..." "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: tag records tags for marked tries
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,ENTER,t,w,o,r,k,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "Tagged 1 try: +work" && grep -q "^2025-11-03-third	work$" "$BULK_TEST_DIR/.try/tags"; then
+    pass
+else
+    fail "Tag should record tags" "2025-11-03-third<TAB>work" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: tags are shown next to the entry
+output=$(try_run --path="$BULK_TEST_DIR" --and-exit exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "2025-11-03-third.*#work"; then
+    pass
+else
+    fail "Tags should be shown on the entry line" "#work" "$output" "tui_spec.md#multi-select"
+fi
+
+# Test: a '-' prefix removes a tag
+try_run --path="$BULK_TEST_DIR" --and-keys='TAB,ENTER,t,-,w,o,r,k,ENTER' exec >/dev/null 2>&1
+if ! grep -q "2025-11-03-third" "$BULK_TEST_DIR/.try/tags"; then
+    pass
+else
+    fail "'-tag' should remove a tag" "no tags for 2025-11-03-third" "$(cat "$BULK_TEST_DIR/.try/tags")" "tui_spec.md#multi-select"
+fi
+
+# Tags follow a try that is renamed or archived and go with a deleted one
+script_only() {
+    sed -n 's/.*\(# if you can read this\)/\1/; /^# if you can read this/,$p'
+}
+TAG_MOVE_DIR=$(mktemp -d)
+mkdir -p "$TAG_MOVE_DIR/2025-11-01-tagged" "$TAG_MOVE_DIR/.try"
+printf '2025-11-01-tagged\tkeep\n' > "$TAG_MOVE_DIR/.try/tags"
+
+# Test: rename carries the tags to the new name
+output=$(try_run --path="$TAG_MOVE_DIR" --and-keys='CTRL-R,CTRL-A,CTRL-K,TYPE=renamed,ENTER' exec 2>/dev/null)
+(eval "$(echo "$output" | script_only)") >/dev/null 2>&1
+if grep -q "^renamed	keep$" "$TAG_MOVE_DIR/.try/tags" && ! grep -q "2025-11-01-tagged" "$TAG_MOVE_DIR/.try/tags"; then
+    pass
+else
+    fail "Rename should keep the tags" "renamed<TAB>keep" "$(cat "$TAG_MOVE_DIR/.try/tags")" "tui_spec.md#multi-select"
+fi
+
+# Test: archive moves the tags into the archive
+output=$(try_run --path="$TAG_MOVE_DIR" --and-keys='TAB,ENTER,a' exec 2>/dev/null)
+(eval "$(echo "$output" | script_only)") >/dev/null 2>&1
+if ! grep -q "renamed" "$TAG_MOVE_DIR/.try/tags" && grep -q "^renamed	keep$" "$TAG_MOVE_DIR/.archive/.try/tags" 2>/dev/null; then
+    pass
+else
+    fail "Archive should move the tags" "renamed<TAB>keep in .archive/.try/tags" "$(cat "$TAG_MOVE_DIR/.try/tags" "$TAG_MOVE_DIR/.archive/.try/tags" 2>&1)" "tui_spec.md#multi-select"
+fi
+
+# Test: delete drops the tags, so a later try of that name starts bare
+mkdir -p "$TAG_MOVE_DIR/2025-11-02-gone"
+printf '2025-11-02-gone\told\n' >> "$TAG_MOVE_DIR/.try/tags"
+output=$(try_run --path="$TAG_MOVE_DIR" --and-keys='TAB,ENTER,d,Y,E,S,ENTER' exec 2>/dev/null)
+(eval "$(echo "$output" | script_only)") >/dev/null 2>&1
+if [ ! -e "$TAG_MOVE_DIR/2025-11-02-gone" ] && ! grep -q "2025-11-02-gone" "$TAG_MOVE_DIR/.try/tags"; then
+    pass
+else
+    fail "Delete should drop the tags" "no 2025-11-02-gone line" "$(cat "$TAG_MOVE_DIR/.try/tags")" "tui_spec.md#multi-select"
+fi
+rm -rf "$TAG_MOVE_DIR"
+
+# Test: delete from the menu still asks for confirmation
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,ENTER,d,Y,E,S,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "rm -rf '2025-11-03-third'"; then
+    pass
+else
+    fail "Delete from the menu should delete after YES" "rm -rf" "$output" "delete_spec.md"
+fi
+
+# Test: Esc clears marks instead of exiting
+output=$(try_run --path="$BULK_TEST_DIR" --and-keys='TAB,ESC,ENTER' exec 2>/dev/null)
+if echo "$output" | grep -q "cd '$BULK_TEST_DIR/2025-11-02-second'"; then
+    pass
+else
+    fail "Esc should clear marks" "cd into the selected try" "$output" "tui_spec.md#multi-select"
+fi
+
+# Cleanup
+rm -rf "$BULK_TEST_DIR" "$BULK_OTHER_DIR"
@@ -0,0 +1,96 @@
+# Sort mode tests
+# Spec: Ctrl-S cycles sort modes shown in the header; --sort picks one
+
+section "sort-modes"
+
+strip_ansi() {
+    sed 's/\x1b\[[0-9;]*[a-zA-Z]//g' | sed 's/\x1b\[[?][0-9]*[a-zA-Z]//g'
+}
+
+# Names of the entries in the order they are listed
+entry_order() {
+    strip_ansi | grep -o "📁 [^ ]*" | sed 's/📁 //' | tr '\n' ' '
+}
+
+SORT_TEST_DIR=$(mktemp -d)
+mkdir -p "$SORT_TEST_DIR/2025-01-01-zeta" "$SORT_TEST_DIR/2025-03-01-alpha" "$SORT_TEST_DIR/2025-02-01-mid"
+head -c 4096 /dev/zero > "$SORT_TEST_DIR/2025-02-01-mid/data"
+touch -t 202502010000 "$SORT_TEST_DIR/2025-03-01-alpha"
+touch -t 202503010000 "$SORT_TEST_DIR/2025-01-01-zeta"
+touch -t 202501010000 "$SORT_TEST_DIR/2025-02-01-mid"
+
+# Test: header shows the default mode
+output=$(try_run --path="$SORT_TEST_DIR" --and-exit exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "Try Directory Selection.*Sort: score"; then
+    pass
+else
+    fail "Header should show the sort mode" "Sort: score" "$output" "tui_spec.md#sort-modes"
+fi
+
+# Test: --sort=recent orders by modification time
+order=$(try_run --path="$SORT_TEST_DIR" --sort=recent --and-exit exec 2>&1 | entry_order)
+if [ "$order" = "2025-01-01-zeta 2025-03-01-alpha 2025-02-01-mid " ]; then
+    pass
+else
+    fail "--sort=recent should list most recent first" "zeta alpha mid" "$order" "command_line.md"
+fi
+
+# Test: --sort=oldest reverses it
+order=$(try_run --path="$SORT_TEST_DIR" --sort=oldest --and-exit exec 2>&1 | entry_order)
+if [ "$order" = "2025-02-01-mid 2025-03-01-alpha 2025-01-01-zeta " ]; then
+    pass
+else
+    fail "--sort=oldest should list least recent first" "mid alpha zeta" "$order" "command_line.md"
+fi
+
+# Test: --sort=name ignores the date prefix
+order=$(try_run --path="$SORT_TEST_DIR" --sort=name --and-exit exec 2>&1 | entry_order)
+if [ "$order" = "2025-03-01-alpha 2025-02-01-mid 2025-01-01-zeta " ]; then
+    pass
+else
+    fail "--sort=name should sort by name without date" "alpha mid zeta" "$order" "tui_spec.md#sort-modes"
+fi
+
+# Test: --sort=created uses the date prefix, newest first
+order=$(try_run --path="$SORT_TEST_DIR" --sort=created --and-exit exec 2>&1 | entry_order)
+if [ "$order" = "2025-03-01-alpha 2025-02-01-mid 2025-01-01-zeta " ]; then
+    pass
+else
+    fail "--sort=created should sort by date prefix" "alpha mid zeta" "$order" "tui_spec.md#sort-modes"
+fi
+
+# Test: --sort=size lists the largest first and shows sizes
+output=$(try_run --path="$SORT_TEST_DIR" --sort=size --and-exit exec 2>&1)
+order=$(echo "$output" | entry_order)
+if [ "${order%% *}" = "2025-02-01-mid" ] && echo "$output" | strip_ansi | grep -q "4.0K, "; then
+    pass
+else
+    fail "--sort=size should list largest first with sizes" "mid first, 4.0K" "$output" "tui_spec.md#sort-modes"
+fi
+
+# Test: Ctrl-S cycles to the next mode
+output=$(try_run --path="$SORT_TEST_DIR" --sort=score --and-keys='CTRL-S' exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "Sort: recent"; then
+    pass
+else
+    fail "Ctrl-S should cycle the sort mode" "Sort: recent" "$output" "tui_spec.md#sort-modes"
+fi
+
+# Test: a filtered list keeps the mode's order
+order=$(try_run --path="$SORT_TEST_DIR" --sort=name --and-type=a --and-exit exec 2>&1 | entry_order)
+if [ "$order" = "2025-03-01-alpha 2025-01-01-zeta " ]; then
+    pass
+else
+    fail "Filtered entries should follow the sort mode" "alpha zeta" "$order" "tui_spec.md#sort-modes"
+fi
+
+# Test: unknown modes are rejected
+output=$(try_run --path="$SORT_TEST_DIR" --sort=bogus exec 2>&1)
+if [ $? -ne 0 ] && echo "$output" | grep -q "unknown sort mode"; then
+    pass
+else
+    fail "Unknown sort mode should be an error" "unknown sort mode" "$output" "command_line.md"
+fi
+
+# Cleanup
+rm -rf "$SORT_TEST_DIR"
@@ -0,0 +1,50 @@
+# Match algorithm tests
+# Spec: match.algorithm selects the greedy or the optimal-alignment scorer
+
+section "match-algorithm"
+
+# Render highlighted characters as [x] and drop other styling
+mark_highlights() {
+    sed 's/\x1b\[1m\x1b\[33m\(.\)\x1b\[39m\x1b\[22m/[\1]/g' | sed 's/\x1b\[[0-9;]*[a-zA-Z]//g'
+}
+
+ALGO_TEST_DIR=$(mktemp -d)
+ALGO_TRIES="$ALGO_TEST_DIR/tries"
+ALGO_CONFIG="$ALGO_TEST_DIR/config.json"
+mkdir -p "$ALGO_TRIES/2025-01-01-config-connection"
+mkdir -p "$ALGO_TRIES/2025-01-02-myConnPool"
+
+# Test: greedy (default) highlights the first occurrence of each character
+output=$(try_run --path="$ALGO_TRIES" --and-type=conn --and-exit exec 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-01-[c][o][n]fig-co[n]nection"; then
+    pass
+else
+    fail "Greedy should take first occurrences" "[c][o][n]fig-co[n]nection" "$output" "performance.md"
+fi
+
+# Test: optimal highlights the best alignment
+echo '{"match": {"algorithm": "optimal"}}' > "$ALGO_CONFIG"
+output=$(TRY_CONFIG="$ALGO_CONFIG" try_run --path="$ALGO_TRIES" --and-type=conn --and-exit exec 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-01-config-[c][o][n][n]ection"; then
+    pass
+else
+    fail "Optimal should match at the word boundary" "config-[c][o][n][n]ection" "$output" "performance.md"
+fi
+
+# Test: optimal prefers camelCase humps
+if echo "$output" | grep -qF "2025-01-02-my[C][o][n][n]Pool"; then
+    pass
+else
+    fail "Optimal should match the camelCase hump" "my[C][o][n][n]Pool" "$output" "performance.md"
+fi
+
+# Test: optimal avoids the date prefix
+output=$(TRY_CONFIG="$ALGO_CONFIG" try_run --path="$ALGO_TRIES" --and-type=2c --and-exit exec 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-01-[c]onfig-connection"; then
+    pass
+else
+    fail "Optimal should favor the name start" "[c]onfig" "$output" "performance.md"
+fi
+
+# Cleanup
+rm -rf "$ALGO_TEST_DIR"
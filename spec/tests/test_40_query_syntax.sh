@@ -0,0 +1,91 @@
+# Query syntax tests
+# Spec: space-separated terms are ANDed; ' ^ $ ! and | modify them
+
+section "query-syntax"
+
+# Render highlighted characters as [x] and drop other styling
+mark_highlights() {
+    sed 's/\x1b\[1m\x1b\[33m\(.\)\x1b\[39m\x1b\[22m/[\1]/g' | sed 's/\x1b\[[0-9;]*[a-zA-Z]//g'
+}
+
+QUERY_TEST_DIR=$(mktemp -d)
+QUERY_TRIES="$QUERY_TEST_DIR/tries"
+mkdir -p "$QUERY_TRIES/2025-01-01-redis-cache"
+mkdir -p "$QUERY_TRIES/2025-01-02-redis-queue"
+mkdir -p "$QUERY_TRIES/2025-01-03-postgres-cache"
+mkdir -p "$QUERY_TRIES/2025-01-04-cache-warmer"
+
+# Render the selector for a query, with or without highlight markers
+query_marked() {
+    try_run --path="$QUERY_TRIES" --and-type="$1" --and-exit exec 2>&1 | mark_highlights
+}
+query() {
+    query_marked "$1" | sed 's/\[\(.\)\]/\1/g'
+}
+
+# Test: space-separated terms must all match
+output=$(query "redis cache")
+if echo "$output" | grep -q "redis-cache" && ! echo "$output" | grep -q "redis-queue" && ! echo "$output" | grep -q "postgres-cache"; then
+    pass
+else
+    fail "Terms should be ANDed" "only redis-cache" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: highlights cover every term
+output=$(query_marked "redis cache")
+if echo "$output" | grep -qF "2025-01-01-[r][e][d][i][s]-[c][a][c][h][e]"; then
+    pass
+else
+    fail "Highlights should cover all terms" "[r][e][d][i][s]-[c][a][c][h][e]" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: ! excludes entries containing the term
+output=$(query "cache !redis")
+if echo "$output" | grep -q "postgres-cache" && echo "$output" | grep -q "cache-warmer" && ! echo "$output" | grep -q "redis-cache"; then
+    pass
+else
+    fail "!term should exclude matches" "postgres-cache and cache-warmer only" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: ^ anchors at the start of the name, after the date prefix
+output=$(query_marked "^cache")
+if echo "$output" | grep -qF "2025-01-04-[c][a][c][h][e]-warmer" && ! echo "$output" | grep -q "redis-cache"; then
+    pass
+else
+    fail "^term should match the name start" "only cache-warmer" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: $ anchors at the end
+output=$(query "cache\$")
+if echo "$output" | grep -q "redis-cache" && echo "$output" | grep -q "postgres-cache" && ! echo "$output" | grep -q "cache-warmer"; then
+    pass
+else
+    fail "term\$ should match the end" "redis-cache and postgres-cache" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: ' matches a substring exactly, not fuzzily
+output=$(query "'rdsc")
+if ! echo "$output" | grep -q "redis-cache"; then
+    pass
+else
+    fail "'term should not match fuzzily" "no redis-cache" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: | matches either term
+output=$(query "queue | postgres")
+if echo "$output" | grep -q "redis-queue" && echo "$output" | grep -q "postgres-cache" && ! echo "$output" | grep -q "cache-warmer"; then
+    pass
+else
+    fail "a | b should match either" "redis-queue and postgres-cache" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Test: creating from a multi-word query slugifies it
+output=$(try_run --path="$QUERY_TRIES" --and-type="new idea" --and-keys="UP,ENTER" exec 2>&1)
+if echo "$output" | grep -q "new-idea"; then
+    pass
+else
+    fail "Create new should slugify spaces" "new-idea" "$output" "fuzzy_matching.md#query-syntax"
+fi
+
+# Cleanup
+rm -rf "$QUERY_TEST_DIR"
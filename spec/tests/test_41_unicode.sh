@@ -0,0 +1,62 @@
+# Unicode tests
+# Spec: matching folds diacritics; layout is measured in terminal cells
+
+section "unicode"
+
+# Render highlighted clusters as [x] and drop other styling
+mark_highlights() {
+    sed 's/\x1b\[1m\x1b\[33m\([^\x1b]*\)\x1b\[39m\x1b\[22m/[\1]/g' | sed 's/\x1b\[[0-9;?]*[a-zA-Z]//g'
+}
+
+UNI_TEST_DIR=$(mktemp -d)
+UNI_TRIES="$UNI_TEST_DIR/tries"
+NFD_NAME="$(printf 'cafe\xcc\x81')-nfd"
+mkdir -p "$UNI_TRIES/2025-01-01-café-notes"
+mkdir -p "$UNI_TRIES/2025-01-02-$NFD_NAME"
+mkdir -p "$UNI_TRIES/2025-01-03-日本語-メモ"
+mkdir -p "$UNI_TRIES/2025-01-04-cafe-plain"
+
+# Test: an ASCII query matches accented names
+output=$(try_run --path="$UNI_TRIES" --and-type=cafe --and-exit exec 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-01-[c][a][f][é]-notes"; then
+    pass
+else
+    fail "cafe should match café" "[c][a][f][é]-notes" "$output" "fuzzy_matching.md#unicode"
+fi
+
+# Test: a decomposed accent is highlighted with its letter
+if echo "$output" | grep -qF "2025-01-02-[c][a][f][$(printf 'e\xcc\x81')]-nfd"; then
+    pass
+else
+    fail "Combining marks should stay with their letter" "[c][a][f][é]-nfd" "$output" "fuzzy_matching.md#unicode"
+fi
+
+# Test: an accented query matches plain names
+output=$(try_run --path="$UNI_TRIES" --and-exit exec café 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-04-[c][a][f][e]-plain"; then
+    pass
+else
+    fail "café should match cafe" "[c][a][f][e]-plain" "$output" "fuzzy_matching.md#unicode"
+fi
+
+# Test: non-Latin letters are word characters and match
+output=$(try_run --path="$UNI_TRIES" --and-exit exec メモ 2>&1 | mark_highlights)
+if echo "$output" | grep -qF "2025-01-03-日本語-[メ][モ]"; then
+    pass
+else
+    fail "Japanese queries should match" "日本語-[メ][モ]" "$output" "fuzzy_matching.md#unicode"
+fi
+
+# Test: wide characters take two cells, so metadata stays right-aligned.
+# "日本語-メモ" is 11 cells against 10 for "cafe-plain": one space less.
+output=$(try_run --path="$UNI_TRIES" --and-exit exec 2>&1 | sed 's/\x1b\[[0-9;?]*[a-zA-Z]//g')
+gap_wide=$(echo "$output" | grep "日本語" | sed 's/.*メモ\( *\)just.*/\1/')
+gap_ascii=$(echo "$output" | grep "cafe-plain" | sed 's/.*plain\( *\)just.*/\1/')
+if [ $((${#gap_ascii} - ${#gap_wide})) -eq 1 ]; then
+    pass
+else
+    fail "Wide names should keep metadata aligned" "gap ${#gap_ascii} - 1" "gap ${#gap_wide}" "tui_spec.md#metadata-positioning"
+fi
+
+# Cleanup
+rm -rf "$UNI_TEST_DIR"
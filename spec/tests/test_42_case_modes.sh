@@ -0,0 +1,85 @@
+# Case mode tests
+# Spec: --case and match.case choose smart, ignore or respect; Ctrl-G cycles
+
+section "case-modes"
+
+CASE_TEST_DIR=$(mktemp -d)
+CASE_TRIES="$CASE_TEST_DIR/tries"
+CASE_CONFIG="$CASE_TEST_DIR/config.json"
+mkdir -p "$CASE_TRIES/2025-01-01-myAPI"
+mkdir -p "$CASE_TRIES/2025-01-01-rapid"
+mkdir -p "$CASE_TRIES/2025-01-02-Readme-notes"
+mkdir -p "$CASE_TRIES/2025-01-02-already"
+
+# List the entry names in display order
+entries() {
+    strip_ansi | grep "📁" | sed 's/.*2025-01-0[0-9]-\([^ ]*\).*/\1/' | tr '\n' ' '
+}
+
+# Test: smart case is the default and shown in the header
+output=$(try_run --path="$CASE_TRIES" --and-exit exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "Case: smart (^G)"; then
+    pass
+else
+    fail "Smart case should be the default" "Case: smart (^G)" "$output" "fuzzy_matching.md#case-sensitivity"
+fi
+
+# Test: a lowercase query ignores case and favors uppercase matches
+order=$(try_run --path="$CASE_TRIES" --and-type=api --and-exit exec 2>&1 | entries)
+if [ "$order" = "myAPI rapid " ]; then
+    pass
+else
+    fail "Smart case should rank uppercase matches first" "myAPI rapid" "$order" "fuzzy_matching.md#case-sensitivity"
+fi
+
+# Test: an uppercase letter makes the query case-sensitive
+order=$(try_run --path="$CASE_TRIES" --and-type=Re --and-exit exec 2>&1 | entries)
+if [ "$order" = "Readme-notes " ]; then
+    pass
+else
+    fail "Smart case should respect case for uppercase queries" "Readme-notes" "$order" "fuzzy_matching.md#case-sensitivity"
+fi
+
+# Test: --case=ignore matches regardless of case
+order=$(try_run --path="$CASE_TRIES" --case=ignore --and-type=Re --and-exit exec 2>&1 | entries)
+if echo "$order" | grep -q "already" && echo "$order" | grep -q "Readme-notes"; then
+    pass
+else
+    fail "--case=ignore should ignore case" "already and Readme-notes" "$order" "command_line.md#global-options"
+fi
+
+# Test: --case=respect matches lowercase queries exactly
+order=$(try_run --path="$CASE_TRIES" --case=respect --and-type=api --and-exit exec 2>&1 | entries)
+if [ "$order" = "rapid " ]; then
+    pass
+else
+    fail "--case=respect should respect case" "rapid" "$order" "command_line.md#global-options"
+fi
+
+# Test: match.case in the config sets the mode
+echo '{"match": {"case": "respect"}}' > "$CASE_CONFIG"
+output=$(TRY_CONFIG="$CASE_CONFIG" try_run --path="$CASE_TRIES" --and-exit exec 2>&1)
+if echo "$output" | strip_ansi | grep -q "Case: respect"; then
+    pass
+else
+    fail "match.case should set the mode" "Case: respect" "$output" "fuzzy_matching.md#case-sensitivity"
+fi
+
+# Test: Ctrl-G cycles the mode and rematches
+order=$(try_run --path="$CASE_TRIES" --and-keys='TYPE=Re,CTRL-G' exec 2>&1 | entries)
+if echo "$order" | grep -q "already"; then
+    pass
+else
+    fail "Ctrl-G should switch to ignore" "already listed" "$order" "tui_spec.md#navigation"
+fi
+
+# Test: an unknown mode is an error
+output=$(try_run --case=loud exec 2>&1)
+if [ $? -ne 0 ] && echo "$output" | grep -q "unknown case mode"; then
+    pass
+else
+    fail "Unknown case mode should fail" "unknown case mode" "$output" "command_line.md#global-options"
+fi
+
+# Cleanup
+rm -rf "$CASE_TEST_DIR"
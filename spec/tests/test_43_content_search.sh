@@ -0,0 +1,118 @@
+# Content search tests
+# Spec: try grep and Alt-G search file names, notes and optionally contents
+
+section "content-search"
+
+GREP_TEST_DIR=$(mktemp -d)
+GREP_TRIES="$GREP_TEST_DIR/tries"
+GREP_CONFIG="$GREP_TEST_DIR/config.json"
+mkdir -p "$GREP_TRIES/2025-01-01-alpha/node_modules/dep"
+mkdir -p "$GREP_TRIES/2025-01-02-beta/src"
+mkdir -p "$GREP_TRIES/2025-01-03-gamma/build"
+mkdir -p "$GREP_TRIES/2025-01-04-delta"
+echo "redis connection pool" > "$GREP_TRIES/2025-01-01-alpha/README.md"
+echo "redis" > "$GREP_TRIES/2025-01-01-alpha/node_modules/dep/README.md"
+printf 'package main\n\n// caches in redis\n' > "$GREP_TRIES/2025-01-02-beta/src/main.go"
+echo "try redis streams" > "$GREP_TRIES/2025-01-02-beta/notes.txt"
+echo "redis: done" > "$GREP_TRIES/2025-01-02-beta/TODO"
+echo "build/" > "$GREP_TRIES/2025-01-03-gamma/.gitignore"
+echo "redis" > "$GREP_TRIES/2025-01-03-gamma/build/README.md"
+touch "$GREP_TRIES/2025-01-03-gamma/redis.conf"
+echo "nothing here" > "$GREP_TRIES/2025-01-04-delta/README.md"
+
+# Render the selector as plain text
+grep_render() {
+    try_run --path="$GREP_TRIES" --and-exit exec grep "$@" 2>&1 | strip_ansi
+}
+
+# Test: tries are ranked by hits in notes and file names
+output=$(grep_render redis)
+order=$(echo "$output" | grep "📁" | sed 's/.*2025-01-0[0-9]-\([a-z]*\).*/\1/' | tr '\n' ' ')
+if [ "$order" = "beta alpha gamma " ]; then
+    pass
+else
+    fail "try grep should rank tries by hits" "beta alpha gamma" "$order" "command_line.md#grep"
+fi
+
+# Test: hit counts replace the score
+if echo "$output" | grep "beta" | grep -q "2 hits" && echo "$output" | grep "alpha" | grep -q "1 hit"; then
+    pass
+else
+    fail "Metadata should show hit counts" "2 hits / 1 hit" "$output" "command_line.md#grep"
+fi
+
+# Test: the selected try's first hit is previewed
+if echo "$output" | grep -q "TODO:1: redis: done"; then
+    pass
+else
+    fail "The first hit should be previewed" "TODO:1: redis: done" "$output" "command_line.md#grep"
+fi
+
+# Test: node_modules and .gitignore'd paths are skipped; file names match
+if echo "$output" | grep "alpha" | grep -q "1 hit" && echo "$output" | grep "gamma" | grep -q "1 hit"; then
+    pass
+else
+    fail "Ignored paths should not count" "alpha and gamma with 1 hit" "$output" "command_line.md#grep"
+fi
+
+# Test: tries without hits are hidden
+if ! echo "$output" | grep -q "delta"; then
+    pass
+else
+    fail "Tries without hits should be hidden" "no delta" "$output" "command_line.md#grep"
+fi
+
+# Test: --contents also searches source files
+output=$(grep_render --contents redis)
+if echo "$output" | grep "beta" | grep -q "3 hits"; then
+    pass
+else
+    fail "--contents should search every text file" "beta with 3 hits" "$output" "command_line.md#grep"
+fi
+
+# Test: grep.contents in the config does the same
+echo '{"grep": {"contents": true}}' > "$GREP_CONFIG"
+output=$(TRY_CONFIG="$GREP_CONFIG" try_run --path="$GREP_TRIES" --and-exit exec grep redis 2>&1 | strip_ansi)
+if echo "$output" | grep "beta" | grep -q "3 hits"; then
+    pass
+else
+    fail "grep.contents should search every text file" "beta with 3 hits" "$output" "command_line.md#grep"
+fi
+
+# Test: Alt-G toggles content search in the selector
+output=$(try_run --path="$GREP_TRIES" --and-keys='ALT-G,TYPE=connection' exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q "Grep: connection" && echo "$output" | grep -q "README.md:1: redis connection pool"; then
+    pass
+else
+    fail "Alt-G should switch to content search" "Grep: connection" "$output" "tui_spec.md#content-search"
+fi
+
+# Test: the empty pattern hint names the key bound to toggle-grep
+echo '{"keys": {"alt-g": "", "ctrl-x": "toggle-grep"}}' > "$GREP_CONFIG"
+output=$(TRY_CONFIG="$GREP_CONFIG" try_run --path="$GREP_TRIES" --and-keys="CTRL-X,ESC" exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q "Type to search inside tries (Ctrl-X: search names)"; then
+    pass
+else
+    fail "The grep hint should show the bound key" "Ctrl-X: search names" "$output" "tui_spec.md#content-search"
+fi
+
+# Test: selecting a result cds into it
+output=$(try_run --path="$GREP_TRIES" --and-keys='ENTER' exec grep streams 2>&1)
+if echo "$output" | grep -q "cd '$GREP_TRIES/2025-01-02-beta'"; then
+    pass
+else
+    fail "Enter should cd into the try" "cd .../2025-01-02-beta" "$output" "command_line.md#grep"
+fi
+
+# Test: control sequences in files never reach the terminal
+mkdir -p "$GREP_TRIES/2025-01-05-epsilon"
+printf 'title \033]0;pwned\007\tsnippet\n' > "$GREP_TRIES/2025-01-05-epsilon/notes.txt"
+output=$(try_run --path="$GREP_TRIES" --and-exit exec grep snippet 2>&1)
+if ! echo "$output" | grep -q $'\x1b]0;' && ! echo "$output" | grep -q $'\x07' && echo "$output" | strip_ansi | grep -q "title \]0;pwned snippet"; then
+    pass
+else
+    fail "snippets should drop control characters" "title ]0;pwned snippet" "$output" "tui_spec.md#content-search"
+fi
+
+# Cleanup
+rm -rf "$GREP_TEST_DIR"
@@ -0,0 +1,104 @@
+# Index tests
+# Spec: the selector keeps an index of tries in <root>/.try/index
+
+section "index"
+
+INDEX_TEST_DIR=$(mktemp -d)
+INDEX_TRIES="$INDEX_TEST_DIR/tries"
+INDEX_FILE="$INDEX_TRIES/.try/index"
+mkdir -p "$INDEX_TRIES/2025-01-01-alpha" "$INDEX_TRIES/2025-01-02-beta"
+
+# Render the selector as plain text, saving the index
+index_render() {
+    try_run --path="$INDEX_TRIES" --and-exit --and-save-index exec "$@" 2>&1 | strip_ansi
+}
+
+# Test: test runs leave the root alone
+try_run --path="$INDEX_TRIES" --and-exit exec >/dev/null 2>&1
+if [ ! -e "$INDEX_TRIES/.try" ]; then
+    pass
+else
+    fail "Test runs should not save the index" "no .try" "$(ls -A "$INDEX_TRIES")" "performance.md#on-disk-index"
+fi
+
+# Test: the first launch writes the index
+index_render >/dev/null
+if head -1 "$INDEX_FILE" 2>/dev/null | grep -q "^# try index v2 depth=1$" && grep -q "2025-01-02-beta" "$INDEX_FILE"; then
+    pass
+else
+    fail "The selector should write an index" "# try index v2 depth=1" "$(cat "$INDEX_FILE" 2>&1)" "performance.md#on-disk-index"
+fi
+
+# Test: a try created after the index was written is listed
+mkdir "$INDEX_TRIES/2025-01-03-gamma"
+output=$(index_render)
+if echo "$output" | grep -q "2025-01-03-gamma" && grep -q "2025-01-03-gamma" "$INDEX_FILE"; then
+    pass
+else
+    fail "A new try should invalidate the index" "2025-01-03-gamma listed" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: the live scan reconciles a stale index without directory changes
+sed -i.bak 's/2025-01-01-alpha/2025-01-09-ghost/' "$INDEX_FILE" && rm -f "$INDEX_FILE.bak"
+output=$(index_render)
+if ! echo "$output" | grep -q "ghost" && ! grep -q "ghost" "$INDEX_FILE" && echo "$output" | grep -q "2025-01-01-alpha"; then
+    pass
+else
+    fail "The live scan should reconcile the index" "alpha listed, no ghost" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: a corrupt index is ignored and rewritten
+echo "garbage" > "$INDEX_FILE"
+output=$(index_render)
+if echo "$output" | grep -q "2025-01-02-beta" && head -1 "$INDEX_FILE" | grep -q "^# try index v2"; then
+    pass
+else
+    fail "A corrupt index should be ignored" "beta listed" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: a removed try drops out of the index
+rm -rf "$INDEX_TRIES/2025-01-03-gamma"
+output=$(index_render)
+if ! echo "$output" | grep -q "gamma" && ! grep -q "2025-01-03-gamma" "$INDEX_FILE"; then
+    pass
+else
+    fail "A removed try should leave the index" "no gamma" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: sizes are cached while fresh, even when deeper files grow
+mkdir -p "$INDEX_TRIES/2025-01-02-beta/src"
+printf '0123456789' > "$INDEX_TRIES/2025-01-02-beta/src/data"
+index_render --sort=size >/dev/null
+printf '0123456789' >> "$INDEX_TRIES/2025-01-02-beta/src/data"
+output=$(index_render --sort=size)
+if echo "$output" | grep "2025-01-02-beta" | grep -q "10B"; then
+    pass
+else
+    fail "A fresh size should come from the index" "10B" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: expired sizes are measured again
+awk -F '\t' 'BEGIN { OFS = "\t" } $1 == "t" { $5 = 0 } { print }' "$INDEX_FILE" > "$INDEX_FILE.tmp" && mv "$INDEX_FILE.tmp" "$INDEX_FILE"
+output=$(index_render --sort=size)
+if echo "$output" | grep "2025-01-02-beta" | grep -q "20B"; then
+    pass
+else
+    fail "An expired size should be measured again" "20B" "$output" "performance.md#on-disk-index"
+fi
+
+# Test: a root that is not writable gets no index
+if [ "$(id -u)" != 0 ]; then
+    READONLY_TRIES="$INDEX_TEST_DIR/readonly"
+    mkdir -p "$READONLY_TRIES/2025-01-01-alpha"
+    chmod 555 "$READONLY_TRIES"
+    output=$(try_run --path="$READONLY_TRIES" --and-exit --and-save-index exec 2>&1 | strip_ansi)
+    chmod 755 "$READONLY_TRIES"
+    if echo "$output" | grep -q "2025-01-01-alpha" && ! echo "$output" | grep -q "Could not save" && [ ! -e "$READONLY_TRIES/.try" ]; then
+        pass
+    else
+        fail "A read-only root should be listed without an index" "alpha listed, no .try" "$output" "performance.md#on-disk-index"
+    fi
+fi
+
+# Cleanup
+rm -rf "$INDEX_TEST_DIR"
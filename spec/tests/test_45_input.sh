@@ -0,0 +1,64 @@
+# Input decoding tests
+# Spec: keys are decoded whole: UTF-8 characters, escape sequences, pastes
+
+section "input"
+
+INPUT_TEST_DIR=$(mktemp -d)
+mkdir -p "$INPUT_TEST_DIR/2025-01-01-café-notes"
+mkdir -p "$INPUT_TEST_DIR/2025-01-02-redis-cache"
+
+# The search line of the last frame
+search_line() {
+    strip_ansi | grep -a "Search:" | tail -1
+}
+
+# Test: a multi-byte character is one key
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="$(printf 'caf\xc3\xa9')" exec 2>&1 | search_line)
+if echo "$output" | grep -q "Search: café"; then
+    pass
+else
+    fail "UTF-8 characters should be typed whole" "Search: café" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Test: backspace deletes a whole character
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="TYPE=café,BACKSPACE,TYPE=x" exec 2>&1 | search_line)
+if echo "$output" | grep -q "Search: cafx"; then
+    pass
+else
+    fail "Backspace should delete one character" "Search: cafx" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Test: a modified arrow is not typed as text
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="$(printf 're\033[1;5Ad')" exec 2>&1 | search_line)
+if echo "$output" | grep -q "Search: red *$"; then
+    pass
+else
+    fail "Ctrl-Up should not type its sequence" "Search: red" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Test: SS3 arrows move like CSI arrows
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="$(printf '\033OB\r')" exec 2>&1)
+if echo "$output" | grep -q "2025-01-01-café-notes\|2025-01-02-redis-cache"; then
+    pass
+else
+    fail "ESC O B should move down" "cd into a try" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Test: pasted text is inserted at once, line breaks as spaces
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="TYPE=r,PASTE=edis" exec 2>&1 | search_line)
+if echo "$output" | grep -q "Search: redis"; then
+    pass
+else
+    fail "A paste should be inserted as text" "Search: redis" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Test: a bracketed paste in raw input is decoded
+output=$(try_run --path="$INPUT_TEST_DIR" --and-keys="$(printf '\033[200~red\nis\033[201~')" exec 2>&1 | search_line)
+if echo "$output" | grep -q "Search: red is"; then
+    pass
+else
+    fail "A bracketed paste should be one key" "Search: red is" "$output" "tui_spec.md#input-decoding"
+fi
+
+# Cleanup
+rm -rf "$INPUT_TEST_DIR"
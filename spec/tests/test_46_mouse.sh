@@ -0,0 +1,96 @@
+# Mouse tests
+# Spec: clicks select entries, double clicks open them, the wheel scrolls
+
+section "mouse"
+
+MOUSE_TEST_DIR=$(mktemp -d)
+MOUSE_TRIES="$MOUSE_TEST_DIR/tries"
+MOUSE_CONFIG="$MOUSE_TEST_DIR/config.json"
+for i in 1 2 3 4 5 6; do
+    mkdir -p "$MOUSE_TRIES/2025-01-0$i-entry$i"
+done
+
+# SGR mouse reports: button, column and row; M presses, m releases
+click() {
+    printf '\033[<0;10;%dM\033[<0;10;%dm' "$1" "$1"
+}
+
+# The basename of the try an --and-keys run selected
+selected_try() {
+    grep -o "cd '[^']*'" | sed "s|.*/||; s|'||"
+}
+
+# The list starts on row 5, below the header and search lines
+output=$(try_run --path="$MOUSE_TRIES" --and-exit exec 2>&1 | strip_ansi | grep "📁" | sed -n 2p | grep -o "entry[0-9]")
+ROW2_TRY="$output"
+
+# Test: a click selects the entry under it
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(click 6; printf '\r')" exec 2>/dev/null | selected_try)
+if [ -n "$ROW2_TRY" ] && echo "$output" | grep -q "$ROW2_TRY"; then
+    pass
+else
+    fail "A click should select the entry under it" "$ROW2_TRY" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: a double click opens the entry
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(click 6; click 6)" exec 2>/dev/null | selected_try)
+if [ -n "$ROW2_TRY" ] && echo "$output" | grep -q "$ROW2_TRY"; then
+    pass
+else
+    fail "A double click should open the entry" "$ROW2_TRY" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: a double click with entries marked only selects
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(printf '\t'; click 6; click 6)" exec 2>&1 | strip_ansi)
+if ! echo "$output" | grep -q "a: Archive" && ! echo "$output" | grep -q "cd '"; then
+    pass
+else
+    fail "A double click with marks should not open the action menu" "no action menu" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: a double click in delete mode does not ask to delete
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(printf '\004'; click 6; click 6)" exec 2>&1 | strip_ansi)
+if ! echo "$output" | grep -q "Type YES"; then
+    pass
+else
+    fail "A double click in delete mode should not confirm deletion" "no delete dialog" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: clicks on different entries are not a double click
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(click 5; click 6)" exec 2>/dev/null)
+if ! echo "$output" | grep -q "cd '"; then
+    pass
+else
+    fail "Clicks on two entries should only select" "no cd" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: the wheel moves the selection three entries
+expected=$(try_run --path="$MOUSE_TRIES" --and-keys="DOWN,DOWN,DOWN,ENTER" exec 2>/dev/null | selected_try)
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(printf '\033[<65;10;6M\r')" exec 2>/dev/null | selected_try)
+if [ -n "$expected" ] && [ "$output" = "$expected" ]; then
+    pass
+else
+    fail "Wheel down should move the selection three entries" "$expected" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: the horizontal wheel leaves the selection alone
+expected=$(try_run --path="$MOUSE_TRIES" --and-keys="ENTER" exec 2>/dev/null | selected_try)
+output=$(try_run --path="$MOUSE_TRIES" --and-keys="$(printf '\033[<67;10;6M\r')" exec 2>/dev/null | selected_try)
+if [ -n "$expected" ] && [ "$output" = "$expected" ]; then
+    pass
+else
+    fail "Horizontal wheel should not move the selection" "$expected" "$output" "tui_spec.md#mouse"
+fi
+
+# Test: mouse: false ignores mouse reports
+echo '{"mouse": false}' > "$MOUSE_CONFIG"
+expected=$(try_run --path="$MOUSE_TRIES" --and-keys="ENTER" exec 2>/dev/null | selected_try)
+output=$(TRY_CONFIG="$MOUSE_CONFIG" try_run --path="$MOUSE_TRIES" --and-keys="$(click 6; printf '\r')" exec 2>/dev/null | selected_try)
+if [ "$output" = "$expected" ]; then
+    pass
+else
+    fail "mouse: false should ignore clicks" "$expected" "$output" "tui_spec.md#mouse"
+fi
+
+# Cleanup
+rm -rf "$MOUSE_TEST_DIR"
@@ -0,0 +1,110 @@
+# Keymap tests
+# Spec: keys are bound to named actions, with emacs and vim presets
+
+section "keymap"
+
+KEYMAP_TEST_DIR=$(mktemp -d)
+KEYMAP_TRIES="$KEYMAP_TEST_DIR/tries"
+KEYMAP_CONFIG="$KEYMAP_TEST_DIR/config.json"
+mkdir -p "$KEYMAP_TRIES/2025-01-01-alpha" "$KEYMAP_TRIES/2025-01-02-beta" "$KEYMAP_TRIES/2025-01-03-gamma"
+
+# The basename of the try an --and-keys run selected
+keymap_selected() {
+    grep -o "cd '[^']*'" | sed "s|.*/||; s|'||"
+}
+
+# The try on the given row of the default list
+keymap_row() {
+    try_run --path="$KEYMAP_TRIES" --and-exit exec 2>&1 | strip_ansi | grep "📁" | sed -n "$1p" | grep -o "2025-[0-9-]*[a-z]*"
+}
+ROW1=$(keymap_row 1)
+ROW3=$(keymap_row 3)
+
+# Test: try keys lists the emacs preset
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run keys 2>&1)
+if echo "$output" | grep -q "Keymap: emacs" && echo "$output" | grep -q "move-up *up, ctrl-p"; then
+    pass
+else
+    fail "try keys should list the bindings" "move-up  up, ctrl-p" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: a configured key moves the selection
+echo '{"keys": {"ctrl-j": "move-down", "ctrl-n": ""}}' > "$KEYMAP_CONFIG"
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys="$(printf '\n\n\r')" exec 2>/dev/null | keymap_selected)
+if [ -n "$ROW3" ] && [ "$output" = "$ROW3" ]; then
+    pass
+else
+    fail "ctrl-j bound to move-down should move down" "$ROW3" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: an empty action unbinds the key
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys="CTRL-N,ENTER" exec 2>/dev/null | keymap_selected)
+if [ -n "$ROW1" ] && [ "$output" = "$ROW1" ]; then
+    pass
+else
+    fail "An unbound ctrl-n should do nothing" "$ROW1" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: try keys shows overrides and reports bad bindings
+echo '{"keys": {"ctrl-j": "move-down", "ctrl-q": "bogus", "hyper-x": "cancel"}}' > "$KEYMAP_CONFIG"
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run keys 2>&1)
+if echo "$output" | grep -q "move-down *down, ctrl-n, ctrl-j" && echo "$output" | grep -q 'unknown action "bogus"' && echo "$output" | grep -q "hyper-x: unknown key"; then
+    pass
+else
+    fail "try keys should show overrides and warnings" "ctrl-j under move-down, two warnings" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: the selector warns about bad bindings when it starts
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys=ESC exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q 'Warning: ignoring key binding: keys: ctrl-q: unknown action "bogus"' && echo "$output" | grep -q "Warning: ignoring key binding: .*hyper-x: unknown key"; then
+    pass
+else
+    fail "the selector should warn about bad bindings" "two warnings" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: the footer is generated from the keymap
+echo '{"keys": {"ctrl-e": "rename", "ctrl-r": ""}}' > "$KEYMAP_CONFIG"
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-exit exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q "\^E: Rename" && ! echo "$output" | grep -q "\^R: Rename"; then
+    pass
+else
+    fail "The footer should show the bound keys" "^E: Rename" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: the header shows the keys that cycle the case and sort modes
+echo '{"keys": {"alt-c": "cycle-case", "ctrl-g": "", "ctrl-s": ""}}' > "$KEYMAP_CONFIG"
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-exit exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q "Case: smart (Alt-C)  Sort: score$" && ! echo "$output" | grep -q "(^G)\|(^S)"; then
+    pass
+else
+    fail "The header should show the bound keys" "Case: smart (Alt-C)  Sort: score" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: vim preset types in insert mode, then Esc leaves j/k to navigate
+echo '{"keymap": "vim"}' > "$KEYMAP_CONFIG"
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys="TYPE=a,ESC,j,j,ENTER" exec 2>/dev/null | keymap_selected)
+expected=$(try_run --path="$KEYMAP_TRIES" --and-keys="TYPE=a,DOWN,DOWN,ENTER" exec 2>/dev/null | keymap_selected)
+if [ -n "$expected" ] && [ "$output" = "$expected" ]; then
+    pass
+else
+    fail "vim normal mode should move with j" "$expected" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: vim normal mode shows its own hints; i returns to typing
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys="ESC,i,TYPE=gam" exec 2>&1 | strip_ansi)
+if echo "$output" | grep -q "k/j: Navigate" && echo "$output" | grep -q "Search: gam"; then
+    pass
+else
+    fail "vim normal mode hints and i to insert" "k/j: Navigate, Search: gam" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Test: vim q leaves the selector
+output=$(TRY_CONFIG="$KEYMAP_CONFIG" try_run --path="$KEYMAP_TRIES" --and-keys="ESC,q,ENTER" exec 2>/dev/null)
+if ! echo "$output" | grep -q "cd '"; then
+    pass
+else
+    fail "q in normal mode should cancel" "no cd" "$output" "tui_spec.md#key-bindings"
+fi
+
+# Cleanup
+rm -rf "$KEYMAP_TEST_DIR"
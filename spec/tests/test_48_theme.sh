@@ -0,0 +1,81 @@
+# Theme tests
+# Spec: style tokens map to a theme's styles, reduced to the terminal's colors
+
+section "theme"
+
+THEME_TEST_DIR=$(mktemp -d)
+THEME_TRIES="$THEME_TEST_DIR/tries"
+THEME_CONFIG="$THEME_TEST_DIR/config.json"
+mkdir -p "$THEME_TRIES/2025-01-01-alpha"
+
+# Render the selector with the theme config, in the given color environment
+theme_render() {
+    TRY_CONFIG="$THEME_CONFIG" COLORTERM="$1" TERM="$2" try_run --path="$THEME_TRIES" --and-exit exec 2>&1
+}
+
+# Test: the dark preset is the default
+echo '{}' > "$THEME_CONFIG"
+output=$(theme_render "" xterm-256color)
+if echo "$output" | grep -q $'\x1b\[48;5;238m' && echo "$output" | grep -q $'\x1b\[38;5;245m'; then
+    pass
+else
+    fail "dark preset should be the default" "[48;5;238m selection, [38;5;245m dim" "$output" "token_system.md#themes"
+fi
+
+# Test: the light preset
+echo '{"theme": "light"}' > "$THEME_CONFIG"
+output=$(theme_render "" xterm-256color)
+if echo "$output" | grep -q $'\x1b\[48;5;254m' && ! echo "$output" | grep -q $'\x1b\[48;5;238m'; then
+    pass
+else
+    fail "light preset should change the selection" "[48;5;254m" "$output" "token_system.md#themes"
+fi
+
+# Test: a theme file overrides tokens, in truecolor when the terminal has it
+cat > "$THEME_TEST_DIR/mine.json" <<'THEME'
+{"preset": "dark", "tokens": {"selected": "bg:#5f0087", "dim": "italic 250"}}
+THEME
+echo '{"theme": "mine.json"}' > "$THEME_CONFIG"
+output=$(theme_render truecolor xterm-256color)
+if echo "$output" | grep -q $'\x1b\[48;2;95;0;135m' && echo "$output" | grep -q $'\x1b\[3m\x1b\[38;5;250m'; then
+    pass
+else
+    fail "theme file should set truecolor styles" "[48;2;95;0;135m and [3m[38;5;250m" "$output" "token_system.md#theme-files"
+fi
+
+# Test: truecolor is reduced to the 256-color palette
+output=$(theme_render "" xterm-256color)
+if echo "$output" | grep -q $'\x1b\[48;5;54m' && ! echo "$output" | grep -q $'\x1b\[48;2;'; then
+    pass
+else
+    fail "#5f0087 should become palette color 54" "[48;5;54m" "$output" "token_system.md#color-depth"
+fi
+
+# Test: 16-color terminals get the basic colors only
+output=$(theme_render "" linux)
+if ! echo "$output" | grep -q $'\x1b\[[34]8;'; then
+    pass
+else
+    fail "linux console should get 16 colors" "no [38; or [48; sequences" "$output" "token_system.md#color-depth"
+fi
+
+# Test: a broken theme file warns and falls back to the dark preset
+echo '{"tokens": {"b": "blinking"}}' > "$THEME_TEST_DIR/mine.json"
+output=$(theme_render "" xterm-256color)
+if echo "$output" | grep -q "Warning: ignoring theme" && echo "$output" | grep -q $'\x1b\[48;5;238m'; then
+    pass
+else
+    fail "bad theme should warn and use dark" "Warning: ignoring theme" "$output" "token_system.md#theme-files"
+fi
+
+# Test: --no-expand-tokens shows the tokens themselves
+echo '{}' > "$THEME_CONFIG"
+output=$(TRY_CONFIG="$THEME_CONFIG" try_run --path="$THEME_TRIES" --and-exit --no-expand-tokens exec 2>&1)
+if echo "$output" | grep -q "{selected}{b}→ {/b}" && echo "$output" | grep -q "{h1} Try Directory Selection{/h1}"; then
+    pass
+else
+    fail "--no-expand-tokens should print tokens" "{selected}{b}→ {/b}" "$output" "test_spec.md#--no-expand-tokens"
+fi
+
+# Cleanup
+rm -rf "$THEME_TEST_DIR"
@@ -0,0 +1,63 @@
+# Entry format tests
+# Spec: entry lines follow the display.format template
+
+section "entry-format"
+
+FORMAT_TEST_DIR=$(mktemp -d)
+FORMAT_TRIES="$FORMAT_TEST_DIR/tries"
+FORMAT_CONFIG="$FORMAT_TEST_DIR/config.json"
+mkdir -p "$FORMAT_TRIES/2025-01-01-alpha/.git" "$FORMAT_TRIES/2025-01-02-beta"
+echo "ref: refs/heads/feature-x" > "$FORMAT_TRIES/2025-01-01-alpha/.git/HEAD"
+
+# The entry lines of the selector, rendered with the format config
+format_lines() {
+    TRY_CONFIG="$FORMAT_CONFIG" try_run --path="$FORMAT_TRIES" --and-exit "$@" exec 2>&1 | strip_ansi | grep "2025-01-0"
+}
+
+# Test: the default format shows age and score on the right
+echo '{}' > "$FORMAT_CONFIG"
+output=$(format_lines)
+if echo "$output" | grep -q "📁 2025-01-01-alpha.* just now, [0-9.]*$"; then
+    pass
+else
+    fail "default format should show age and score" "📁 name ... just now, 2.0" "$output" "tui_spec.md#entry-format"
+fi
+
+# Test: a format without the score hides it
+echo '{"display": {"format": "{icon} {name}{right}{age}"}}' > "$FORMAT_CONFIG"
+output=$(format_lines)
+if echo "$output" | grep -q "alpha  *just now$" && ! echo "$output" | grep -q ", [0-9]"; then
+    pass
+else
+    fail "format without {score} should hide it" "just now at the end" "$output" "tui_spec.md#entry-format"
+fi
+
+# Test: git branch, and a group dropped when its fields are empty
+echo '{"display": {"format": "{name}{right}[({git}) ]{date}"}}' > "$FORMAT_CONFIG"
+output=$(format_lines)
+if echo "$output" | grep -q "alpha  *(feature-x) 2025-01-01$" && echo "$output" | grep -q "beta  *2025-01-02$"; then
+    pass
+else
+    fail "{git} should show the branch, empty groups dropped" "(feature-x) 2025-01-01 / 2025-01-02" "$output" "tui_spec.md#entry-format"
+fi
+
+# Test: style tokens in the format are expanded
+echo '{"display": {"format": "{name}{right}{h2}{age}{/h2}"}}' > "$FORMAT_CONFIG"
+output=$(TRY_CONFIG="$FORMAT_CONFIG" try_run --path="$FORMAT_TRIES" --and-exit exec 2>&1)
+if echo "$output" | grep -q $'\x1b\[1m\x1b\[34mjust now\x1b\[39m\x1b\[22m'; then
+    pass
+else
+    fail "style tokens in the format should expand" "bold blue age" "$output" "tui_spec.md#entry-format"
+fi
+
+# Test: emoji can be turned off
+echo '{"display": {"emoji": false}}' > "$FORMAT_CONFIG"
+output=$(format_lines --and-keys="TAB")
+if echo "$output" | grep -q "  \* 2025-01-01-alpha" && echo "$output" | grep -q "→ / 2025-01-02-beta" && ! echo "$output" | grep -q "📁"; then
+    pass
+else
+    fail "emoji off should use plain icons" "* for marked, / for folders" "$output" "tui_spec.md#entry-format"
+fi
+
+# Cleanup
+rm -rf "$FORMAT_TEST_DIR"
@@ -0,0 +1,61 @@
+# Help overlay and status line tests
+# Spec: ? or F1 shows the bindings and query syntax; status messages time out
+
+section "help"
+
+HELP_TEST_DIR=$(mktemp -d)
+HELP_TRIES="$HELP_TEST_DIR/tries"
+HELP_CONFIG="$HELP_TEST_DIR/config.json"
+mkdir -p "$HELP_TRIES/2025-01-01-alpha" "$HELP_TRIES/2025-01-02-beta"
+echo '{}' > "$HELP_CONFIG"
+
+help_run() {
+    TRY_CONFIG="$HELP_CONFIG" try_run --path="$HELP_TRIES" --and-keys="$1" exec 2>&1
+}
+
+# Test: ? shows the bindings and the query syntax
+output=$(help_run "?" | strip_ansi)
+if echo "$output" | grep -q "Help" && echo "$output" | grep -q "Move the selection up" && echo "$output" | grep -q "Query syntax" && echo "$output" | grep -q "Press any key to close"; then
+    pass
+else
+    fail "? should show the help overlay" "bindings and query syntax" "$output" "tui_spec.md#help"
+fi
+
+# Test: F1 shows it too
+output=$(help_run "F1,ESC" | strip_ansi)
+if echo "$output" | grep -q "Query syntax"; then
+    pass
+else
+    fail "F1 should show the help overlay" "Query syntax" "$output" "tui_spec.md#help"
+fi
+
+# Test: the key that closes help does nothing else
+first=$(help_run "ENTER" | grep -o "cd '[^']*'")
+output=$(help_run "?,DOWN,ENTER" | grep -o "cd '[^']*'")
+if [ -n "$first" ] && [ "$output" = "$first" ]; then
+    pass
+else
+    fail "closing help should swallow the key" "$first" "$output" "tui_spec.md#help"
+fi
+
+# Test: vim normal mode lists its own keys
+echo '{"keymap": "vim"}' > "$HELP_CONFIG"
+output=$(help_run "ESC,?" | strip_ansi)
+if echo "$output" | grep -q "Normal mode keys" && echo "$output" | grep -q "k ↑ ^P"; then
+    pass
+else
+    fail "help in normal mode should list normal mode keys" "Normal mode keys" "$output" "tui_spec.md#help"
+fi
+echo '{}' > "$HELP_CONFIG"
+
+# Test: a status message outlives the next key
+output=$(help_run "CTRL-D,ENTER,TYPE=no,ENTER,DOWN,UP" | strip_ansi)
+count=$(echo "$output" | grep -o "Delete cancelled" | wc -l)
+if [ "$count" -ge 3 ]; then
+    pass
+else
+    fail "status should stay for its timeout" "Delete cancelled in 3 frames" "$count frames" "tui_spec.md#status-line"
+fi
+
+# Cleanup
+rm -rf "$HELP_TEST_DIR"
@@ -0,0 +1,102 @@
+# Inline mode tests
+# Spec: --height and --inline draw in a region below the prompt instead of
+# on the alternate screen
+
+section "inline"
+
+INLINE_TEST_DIR=$(mktemp -d)
+INLINE_TRIES="$INLINE_TEST_DIR/tries"
+INLINE_CONFIG="$INLINE_TEST_DIR/config.json"
+mkdir -p "$INLINE_TRIES/2025-01-01-alpha" "$INLINE_TRIES/2025-01-02-beta"
+echo '{}' > "$INLINE_CONFIG"
+
+inline_run() {
+    TRY_CONFIG="$INLINE_CONFIG" TRY_WIDTH=80 TRY_HEIGHT=30 try_run --path="$INLINE_TRIES" --and-exit "$@" exec
+}
+
+# Runs the selector through a key, drawing every frame
+inline_session() {
+    TRY_CONFIG="$INLINE_CONFIG" TRY_WIDTH=80 TRY_HEIGHT=30 try_run --path="$INLINE_TRIES" --and-keys=ESC "$@" exec
+}
+
+# Lines drawn in the frame, counted by the footer's position
+frame_rows() {
+    strip_ansi | grep -n "Navigate" | tail -1 | cut -d: -f1
+}
+
+# Test: the full screen selector draws from the screen's home
+output=$(inline_session)
+if echo "$output" | grep -q $'\x1b\[H'; then
+    pass
+else
+    fail "full screen mode should draw from the top of the screen" "\\e[H" "$output" "tui_spec.md#inline-mode"
+fi
+
+# Test: --height takes a row count
+rows=$(inline_run --height=12 | frame_rows)
+if [ "$rows" = "12" ]; then
+    pass
+else
+    fail "--height=12 should draw 12 rows inline" "12" "$rows" "tui_spec.md#inline-mode"
+fi
+
+# Test: inline mode draws from the saved cursor, never from the screen's home
+output=$(inline_session --height=12)
+if ! echo "$output" | grep -q $'\x1b\[H' && echo "$output" | grep -q $'\x1b8'; then
+    pass
+else
+    fail "inline mode should draw relative to the prompt" "no \\e[H" "$output" "tui_spec.md#inline-mode"
+fi
+
+# Test: a percentage is of the terminal's rows
+rows=$(inline_run --height=50% | frame_rows)
+if [ "$rows" = "15" ]; then
+    pass
+else
+    fail "--height=50% should take half of 30 rows" "15" "$rows" "tui_spec.md#inline-mode"
+fi
+
+# Test: --inline uses 40%, with room kept for a few entries
+rows=$(inline_run --inline | frame_rows)
+if [ "$rows" = "12" ]; then
+    pass
+else
+    fail "--inline should take 40% of 30 rows" "12" "$rows" "tui_spec.md#inline-mode"
+fi
+
+# Test: small heights are raised to the minimum
+rows=$(inline_run --height=3 | frame_rows)
+if [ "$rows" = "10" ]; then
+    pass
+else
+    fail "--height=3 should be raised to 10 rows" "10" "$rows" "tui_spec.md#inline-mode"
+fi
+
+# Test: the height can be set in the config file
+echo '{"height": "14"}' > "$INLINE_CONFIG"
+rows=$(inline_run | frame_rows)
+if [ "$rows" = "14" ]; then
+    pass
+else
+    fail "height in the config should turn on inline mode" "14" "$rows" "tui_spec.md#inline-mode"
+fi
+echo '{}' > "$INLINE_CONFIG"
+
+# Test: an invalid height is an error
+output=$(TRY_CONFIG="$INLINE_CONFIG" try_run --path="$INLINE_TRIES" --height=tall exec 2>&1)
+status=$?
+if [ $status -ne 0 ] && echo "$output" | grep -q "invalid height"; then
+    pass
+else
+    fail "--height=tall should be rejected" "invalid height, non-zero exit" "$output (exit $status)" "command_line.md#global-options"
+fi
+
+# Test: selecting still works inline
+output=$(TRY_CONFIG="$INLINE_CONFIG" try_run --path="$INLINE_TRIES" --height=12 --and-keys="TYPE=beta,ENTER" exec 2>/dev/null)
+if echo "$output" | grep -q "2025-01-02-beta"; then
+    pass
+else
+    fail "selecting inline should cd into the try" "2025-01-02-beta" "$output" "tui_spec.md#inline-mode"
+fi
+
+rm -rf "$INLINE_TEST_DIR"
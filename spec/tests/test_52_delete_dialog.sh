@@ -0,0 +1,133 @@
+# Delete dialog tests
+# Spec: the dialog shows each try's size and the git work deleting it would
+# lose, and unpushed commits take a second confirmation
+
+section "delete-dialog"
+
+DD_TEST_DIR=$(mktemp -d)
+DD_TRIES="$DD_TEST_DIR/tries"
+mkdir -p "$DD_TRIES"
+
+dd_commit() {
+    echo "$2" >> "$1/notes.txt"
+    git -C "$1" add notes.txt && git -C "$1" -c user.name=try -c user.email=try@example.com commit -qm "$2"
+}
+
+# A plain directory, a pushed repository, one with local work, and worktrees
+# of a living and of a removed repository
+mkdir -p "$DD_TRIES/2025-01-01-plain"
+printf '0123456789' > "$DD_TRIES/2025-01-01-plain/data"
+git init -q --bare "$DD_TEST_DIR/remote.git"
+git init -q "$DD_TRIES/2025-01-02-pushed"
+dd_commit "$DD_TRIES/2025-01-02-pushed" one
+git -C "$DD_TRIES/2025-01-02-pushed" remote add origin "$DD_TEST_DIR/remote.git"
+git -C "$DD_TRIES/2025-01-02-pushed" push -q origin HEAD 2>/dev/null
+git init -q "$DD_TRIES/2025-01-03-local"
+dd_commit "$DD_TRIES/2025-01-03-local" one
+dd_commit "$DD_TRIES/2025-01-03-local" two
+echo draft > "$DD_TRIES/2025-01-03-local/draft.txt"
+git init -q "$DD_TEST_DIR/source"
+dd_commit "$DD_TEST_DIR/source" one
+git -C "$DD_TEST_DIR/source" worktree add -q --detach "$DD_TRIES/2025-01-04-worktree"
+git init -q "$DD_TEST_DIR/removed"
+dd_commit "$DD_TEST_DIR/removed" one
+git -C "$DD_TEST_DIR/removed" worktree add -q --detach "$DD_TRIES/2025-01-05-orphan"
+rm -rf "$DD_TEST_DIR/removed"
+
+# Marks the try matching $1 and opens the dialog, then sends $2
+dd_run() {
+    TRY_WIDTH=120 try_run --path="$DD_TRIES" --and-keys="TYPE=$1,CTRL-D,ENTER${2:+,$2}" exec
+}
+
+dialog_line() {
+    strip_ansi | grep -a "🗑️ $1"
+}
+
+# Test: sizes are shown
+output=$(dd_run plain | dialog_line 2025-01-01-plain)
+if echo "$output" | grep -q "10B"; then
+    pass
+else
+    fail "delete dialog should show the size" "10B" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: uncommitted changes and unpushed commits are shown
+output=$(dd_run local | dialog_line 2025-01-03-local)
+if echo "$output" | grep -q "uncommitted changes" && echo "$output" | grep -q "2 unpushed commits"; then
+    pass
+else
+    fail "delete dialog should show local git work" "uncommitted changes, 2 unpushed commits" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: a pushed repository has nothing to warn about
+output=$(dd_run pushed | dialog_line 2025-01-02-pushed)
+if [ -n "$output" ] && ! echo "$output" | grep -q "uncommitted\|unpushed"; then
+    pass
+else
+    fail "a pushed repository should show no warnings" "no warnings" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: a worktree names its source repository
+output=$(dd_run worktree | dialog_line 2025-01-04-worktree)
+if echo "$output" | grep -q "worktree of .*source"; then
+    pass
+else
+    fail "a worktree should name its source repository" "worktree of .../source" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: a worktree whose repository is gone says so
+output=$(dd_run orphan | dialog_line 2025-01-05-orphan)
+if echo "$output" | grep -q "source repo missing"; then
+    pass
+else
+    fail "a worktree without its repository should say so" "source repo missing" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: YES deletes a try without unpushed work
+output=$(dd_run pushed "TYPE=YES,ENTER" 2>/dev/null)
+if echo "$output" | grep -q "rm -rf '2025-01-02-pushed'"; then
+    pass
+else
+    fail "YES should delete a pushed repository" "rm -rf" "$output" "delete_spec.md#step-4-type-yes-to-delete"
+fi
+
+# Test: YES alone does not delete unpushed commits
+output=$(dd_run local "TYPE=YES,ENTER")
+if ! echo "$output" | grep -q "rm -rf" && echo "$output" | strip_ansi | grep -q "Type DELETE to lose unpushed commits"; then
+    pass
+else
+    fail "unpushed commits should need a second confirmation" "Type DELETE prompt, no rm -rf" "$output" "delete_spec.md#unpushed-commits"
+fi
+
+# Test: the warning counts the tries with unpushed commits
+if echo "$output" | strip_ansi | grep -q "1 try has unpushed commits"; then
+    pass
+else
+    fail "the dialog should warn about unpushed commits" "1 try has unpushed commits" "$output" "delete_spec.md#unpushed-commits"
+fi
+
+# Test: DELETE after YES deletes them
+output=$(dd_run local "TYPE=YES,ENTER,TYPE=DELETE,ENTER" 2>/dev/null)
+if echo "$output" | grep -q "rm -rf '2025-01-03-local'"; then
+    pass
+else
+    fail "YES then DELETE should delete unpushed commits" "rm -rf" "$output" "delete_spec.md#unpushed-commits"
+fi
+
+# Test: anything else at the second prompt cancels
+output=$(dd_run local "TYPE=YES,ENTER,TYPE=YES,ENTER" 2>/dev/null)
+if ! echo "$output" | grep -q "rm -rf"; then
+    pass
+else
+    fail "only DELETE should confirm losing unpushed commits" "no rm -rf" "$output" "delete_spec.md#unpushed-commits"
+fi
+
+# Test: --and-confirm only answers the first prompt
+output=$(TRY_WIDTH=120 try_run --path="$DD_TRIES" --and-keys="TYPE=local,CTRL-D,ENTER" --and-confirm=YES exec 2>/dev/null)
+if ! echo "$output" | grep -q "rm -rf"; then
+    pass
+else
+    fail "--and-confirm=YES should not delete unpushed commits" "no rm -rf" "$output" "delete_spec.md#unpushed-commits"
+fi
+
+rm -rf "$DD_TEST_DIR"
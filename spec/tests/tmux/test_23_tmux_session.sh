@@ -0,0 +1,80 @@
+# Session integration tests (--tmux) on a private tmux socket
+# Tests: session creation, reuse, cwd, switch-client inside tmux
+
+section "tmux-session"
+
+source "$(dirname "$0")/tmux_helpers.sh"
+
+# Setup test directory and a private tmux server (TMUX_TMPDIR picks the socket dir)
+SESSION_TEST_DIR=$(mktemp -d)
+SESSION_SOCKET_DIR=$(mktemp -d)
+mkdir -p "$SESSION_TEST_DIR/2025-11-01-alpha"
+mkdir -p "$SESSION_TEST_DIR/2025-11-02-beta.v2"
+touch -t 202511010000 "$SESSION_TEST_DIR/2025-11-01-alpha"
+touch "$SESSION_TEST_DIR/2025-11-02-beta.v2"  # Most recent, selected first
+
+private_tmux() {
+    TMUX= TMUX_TMPDIR="$SESSION_SOCKET_DIR" tmux "$@"
+}
+
+# Runs the script emitted for selecting the first entry; attach fails without
+# a terminal, but the session is created before it
+run_session_script() {
+    local script
+    script=$(TMUX= $TRY_CMD --path="$SESSION_TEST_DIR" --tmux exec --and-keys="$1" 2>/dev/null)
+    (TMUX= TMUX_TMPDIR="$SESSION_SOCKET_DIR" eval "$script") >/dev/null 2>&1
+    echo "$script"
+}
+
+# Test: selecting a try creates a session named after it
+output=$(run_session_script "ENTER")
+if private_tmux has-session -t "=2025-11-02-beta-v2" 2>/dev/null; then
+    pass
+else
+    fail "--tmux should create a session named after the try" "session 2025-11-02-beta-v2" "$output"
+fi
+
+# Test: session starts in the try directory
+session_path=$(private_tmux list-sessions -F '#{session_name} #{session_path}' 2>/dev/null | grep "^2025-11-02-beta-v2 " | cut -d' ' -f2-)
+if [ "$session_path" = "$SESSION_TEST_DIR/2025-11-02-beta.v2" ]; then
+    pass
+else
+    fail "Session cwd should be the try" "$SESSION_TEST_DIR/2025-11-02-beta.v2" "$session_path"
+fi
+
+# Test: selecting again reuses the session
+run_session_script "ENTER" >/dev/null
+count=$(private_tmux list-sessions -F '#{session_name}' 2>/dev/null | grep -c "^2025-11-02-beta-v2$")
+if [ "$count" = "1" ]; then
+    pass
+else
+    fail "Selecting again should reuse the session" "1 session" "$(private_tmux list-sessions 2>&1)"
+fi
+
+# Test: --tmux does not cd the calling shell
+output=$(run_session_script "DOWN,ENTER")
+if ! echo "$output" | grep -q "^  cd '"; then
+    pass
+else
+    fail "--tmux should not cd the current shell" "no cd line" "$output"
+fi
+
+# Test: inside tmux the client switches to the session (real key injection)
+tui_start "$TRY_CMD --path='$SESSION_TEST_DIR' --tmux exec"
+tui_wait 0.3
+tui_send Enter
+tui_wait 0.3
+tui_assert_substr "tmux switch-client" "Inside tmux, --tmux should switch the client"
+
+# Test: --no-session overrides a session configured as default
+echo '{"session": "tmux"}' > "$SESSION_TEST_DIR/config.json"
+output=$(TRY_CONFIG="$SESSION_TEST_DIR/config.json" $TRY_CMD --path="$SESSION_TEST_DIR" --no-session exec --and-keys=ENTER 2>/dev/null)
+if echo "$output" | grep -q "cd '" && ! echo "$output" | grep -q "tmux"; then
+    pass
+else
+    fail "--no-session should cd instead of opening tmux" "cd, no tmux" "$output"
+fi
+
+# Cleanup
+private_tmux kill-server 2>/dev/null
+rm -rf "$SESSION_TEST_DIR" "$SESSION_SOCKET_DIR"
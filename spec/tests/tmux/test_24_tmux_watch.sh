@@ -0,0 +1,55 @@
+# Filesystem watching tests using tmux for a live selector
+# Tests: tries created or deleted elsewhere show up while the selector is
+# open, and the cursor stays on its try as the list changes
+
+section "tmux-watch"
+
+source "$(dirname "$0")/tmux_helpers.sh"
+
+# Setup test directory
+WATCH_TEST_DIR=$(mktemp -d)
+mkdir -p "$WATCH_TEST_DIR/2025-11-01-alpha"
+mkdir -p "$WATCH_TEST_DIR/2025-11-02-beta"
+
+# Test: a try created by another process appears
+tui_start "$TRY_CMD --path='$WATCH_TEST_DIR' exec"
+tui_wait 0.3
+mkdir -p "$WATCH_TEST_DIR/2025-11-03-gamma"
+tui_wait 1.5
+tui_assert_substr "2025-11-03-gamma" "A try created elsewhere should appear"
+
+# Test: a try deleted by another process disappears
+rm -rf "$WATCH_TEST_DIR/2025-11-01-alpha"
+tui_wait 1.5
+tui_capture >/dev/null
+if ! echo "$TUI_LAST_OUTPUT" | grep -q "2025-11-01-alpha" && echo "$TUI_LAST_OUTPUT" | grep -q "2025-11-02-beta"; then
+    pass
+else
+    fail "A try deleted elsewhere should disappear" "no alpha, beta listed" "$TUI_LAST_OUTPUT"
+fi
+
+tui_send Escape
+
+# Test: the cursor stays on its try when a new one sorts above it
+rm -rf "$WATCH_TEST_DIR"/*
+mkdir -p "$WATCH_TEST_DIR/2025-11-01-older" "$WATCH_TEST_DIR/2025-11-02-newer"
+touch -t 202511010000 "$WATCH_TEST_DIR/2025-11-01-older"
+touch -t 202511020000 "$WATCH_TEST_DIR/2025-11-02-newer"
+tui_start "$TRY_CMD --path='$WATCH_TEST_DIR' exec"
+tui_wait 0.3
+tui_send Down
+tui_wait 0.2
+mkdir -p "$WATCH_TEST_DIR/2025-11-03-newest"
+tui_wait 1.5
+tui_capture >/dev/null
+selected=$(echo "$TUI_LAST_OUTPUT" | grep "→" | grep -o "2025-11-0[0-9]-[a-z]*")
+first=$(echo "$TUI_LAST_OUTPUT" | grep -o "2025-11-0[0-9]-[a-z]*" | head -1)
+if [ "$selected" = "2025-11-01-older" ] && [ "$first" = "2025-11-03-newest" ]; then
+    pass
+else
+    fail "The cursor should stay on its try as the list changes" "2025-11-01-older selected below 2025-11-03-newest" "$TUI_LAST_OUTPUT"
+fi
+
+# Cleanup
+tui_send Escape
+rm -rf "$WATCH_TEST_DIR"
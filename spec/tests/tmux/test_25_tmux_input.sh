@@ -0,0 +1,64 @@
+# Input decoding tests using tmux for real key injection
+# Tests: fast typing, Alt keys, modified arrows, bracketed paste, lone Escape
+
+section "tmux-input"
+
+source "$(dirname "$0")/tmux_helpers.sh"
+
+# Setup test directory
+INPUT_TEST_DIR=$(mktemp -d)
+mkdir -p "$INPUT_TEST_DIR/2025-11-01-alpha-project"
+mkdir -p "$INPUT_TEST_DIR/2025-11-02-beta-test"
+
+# Test: text arriving in one read is typed whole
+tui_start "$TRY_CMD --path='$INPUT_TEST_DIR' exec"
+tui_wait 0.3
+tui_type "alpha-proj"
+tui_wait 0.2
+tui_assert_substr "Search: alpha-proj" "Fast typing should keep every character"
+
+# Test: Alt-G is decoded as one key
+tui_send M-g
+tui_wait 0.2
+tui_assert_substr "Grep: alpha-proj" "Alt-G should toggle content search"
+
+# Test: Ctrl-Up is ignored rather than typed
+tui_start "$TRY_CMD --path='$INPUT_TEST_DIR' exec"
+tui_wait 0.3
+tui_type "be"
+tui_send C-Up
+tui_type "ta"
+tui_wait 0.2
+tui_assert_re "Search: beta *$" "Ctrl-Up should not type its sequence"
+
+# Test: a bracketed paste is inserted as text
+tui_start "$TRY_CMD --path='$INPUT_TEST_DIR' exec"
+tui_wait 0.3
+tmux set-buffer -t "$TUI_SESSION" "beta-te"
+tmux paste-buffer -p -t "$TUI_SESSION"
+tui_wait 0.2
+tui_assert_substr "Search: beta-te" "A paste should be inserted as text"
+
+# Test: a lone Escape still cancels
+tui_send Escape
+tui_wait 0.3
+tui_capture >/dev/null
+if ! echo "$TUI_LAST_OUTPUT" | grep -q "Search:"; then
+    pass
+else
+    fail "Escape should exit the selector" "selector closed" "$TUI_LAST_OUTPUT"
+fi
+
+# Test: keys typed right after the selector closes reach the next reader
+echo 'export INPUT_RC=1' > "$INPUT_TEST_DIR/2025-11-01-alpha-project/.tryrc"
+echo '{"tryrc": {"enabled": true}}' > "$INPUT_TEST_DIR/config.json"
+tui_start "XDG_DATA_HOME='$INPUT_TEST_DIR/data' TRY_CONFIG='$INPUT_TEST_DIR/config.json' $TRY_CMD --path='$INPUT_TEST_DIR' exec alpha"
+tui_wait 0.3
+tui_send Enter
+tui_type y
+tui_send Enter
+tui_wait 0.5
+tui_assert_substr "eval 'export INPUT_RC=1" "The trust prompt should get the first key typed after the selector"
+
+# Cleanup
+rm -rf "$INPUT_TEST_DIR"
@@ -0,0 +1,51 @@
+# Inline mode tests using tmux
+# Tests: --height draws below the prompt, keeps earlier output and clears up
+
+section "tmux-inline"
+
+source "$(dirname "$0")/tmux_helpers.sh"
+
+# Setup test directory
+INLINE_TEST_DIR=$(mktemp -d)
+mkdir -p "$INLINE_TEST_DIR/2025-11-01-alpha-project"
+mkdir -p "$INLINE_TEST_DIR/2025-11-02-beta-test"
+
+# Earlier output, then the selector in 12 rows, then a marker once it exits
+INLINE_CMD="sh -c 'echo earlier-output; $TRY_CMD --path=\"$INLINE_TEST_DIR\" --height=12 exec >/dev/null; echo after-exit; sleep 5'"
+
+# Test: earlier output stays on screen above the selector
+tui_start "$INLINE_CMD"
+tui_wait 0.3
+tui_capture >/dev/null
+first_line=$(echo "$TUI_LAST_OUTPUT" | sed -n 1p)
+if [ "$first_line" = "earlier-output" ] && echo "$TUI_LAST_OUTPUT" | grep -q "Search:"; then
+    pass
+else
+    fail "Inline selector should draw below earlier output" "earlier-output, then the selector" "$TUI_LAST_OUTPUT"
+fi
+
+# Test: the selector takes the requested rows only
+rows=$(echo "$TUI_LAST_OUTPUT" | grep -n "Navigate" | cut -d: -f1)
+if [ "$rows" = "13" ]; then
+    pass
+else
+    fail "Inline selector should take 12 rows" "footer on row 13" "row $rows"
+fi
+
+# Test: typing works inline
+tui_type "beta"
+tui_wait 0.2
+tui_assert_substr "Search: beta" "Typing should update the inline selector"
+
+# Test: leaving clears the region and keeps earlier output
+tui_send Escape
+tui_wait 0.3
+tui_capture >/dev/null
+if echo "$TUI_LAST_OUTPUT" | sed -n 1p | grep -q "earlier-output" && echo "$TUI_LAST_OUTPUT" | sed -n 2p | grep -q "after-exit" && ! echo "$TUI_LAST_OUTPUT" | grep -q "Search:"; then
+    pass
+else
+    fail "Inline selector should clear its region on exit" "earlier-output, after-exit" "$TUI_LAST_OUTPUT"
+fi
+
+# Cleanup
+rm -rf "$INLINE_TEST_DIR"